@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// max depth of decoding call data nested in bytes arguments
+	maxCallDataDepth = 3
+
+	// decimals of the called contract is unknown
+	unknownDecimals = -1
+)
+
+var (
+	errWrongMethodSignature = errors.New("wrong method signature")
+
+	// tokenDecimals decimals of tokens specified by command flags,
+	// the integer arguments of calling these tokens are also shown in decimals.
+	tokenDecimals = make(map[common.Address]int)
+)
+
+// loadTokenDecimals loads token decimals of command flags, eg. `0x...:6`
+func loadTokenDecimals(ctx *cli.Context) error {
+	for _, arg := range ctx.StringSlice(tokenDecimalsFlag.Name) {
+		parts := strings.Split(arg, ":")
+		if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+			return fmt.Errorf("wrong token decimals '%v'", arg)
+		}
+		decimals, err := strconv.ParseUint(parts[1], 10, 8)
+		if err != nil {
+			return fmt.Errorf("wrong token decimals '%v'", arg)
+		}
+		tokenDecimals[common.HexToAddress(parts[0])] = int(decimals)
+	}
+	return nil
+}
+
+// decimalsOfToken returns the decimals of token, or unknown decimals
+func decimalsOfToken(token *common.Address) int {
+	if token == nil {
+		return unknownDecimals
+	}
+	if decimals, exist := tokenDecimals[*token]; exist {
+		return decimals
+	}
+	return unknownDecimals
+}
+
+// parseMethodSignature parse method signature (eg. `f(uint256,(address,bytes)[])`),
+// the arguments are named by their positions as `argN`.
+func parseMethodSignature(sig string) (*abi.Method, error) {
+	sig = strings.ReplaceAll(sig, " ", "")
+	start := strings.Index(sig, "(")
+	if start <= 0 || !strings.HasSuffix(sig, ")") {
+		return nil, fmt.Errorf("%w '%v'", errWrongMethodSignature, sig)
+	}
+	name := sig[:start]
+	args, err := parseSignatureArguments(sig[start+1 : len(sig)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%w '%v', %v", errWrongMethodSignature, sig, err)
+	}
+	inputs := make(abi.Arguments, len(args))
+	for i, arg := range args {
+		typ, err := abi.NewType(arg.Type, "", arg.Components)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%v', %v", errWrongMethodSignature, sig, err)
+		}
+		inputs[i] = abi.Argument{Name: arg.Name, Type: typ}
+	}
+	method := abi.NewMethod(name, name, abi.Function, "", false, false, inputs, nil)
+	return &method, nil
+}
+
+func parseSignatureArguments(argsStr string) ([]abi.ArgumentMarshaling, error) {
+	if argsStr == "" {
+		return nil, nil
+	}
+	var parts []string
+	var depth, start int
+	for i, c := range argsStr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, errors.New("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, argsStr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("unbalanced parentheses")
+	}
+	parts = append(parts, argsStr[start:])
+
+	args := make([]abi.ArgumentMarshaling, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return nil, errors.New("empty argument type")
+		}
+		args[i] = abi.ArgumentMarshaling{Name: fmt.Sprintf("arg%d", i), Type: part}
+		if !strings.HasPrefix(part, "(") {
+			continue
+		}
+		end := strings.LastIndex(part, ")")
+		components, err := parseSignatureArguments(part[1:end])
+		if err != nil {
+			return nil, err
+		}
+		args[i].Type = "tuple" + part[end+1:]
+		args[i].Components = components
+	}
+	return args, nil
+}
+
+// printCallData prints the method and decoded arguments of call data,
+// all candidates are shown if the selector collides.
+// integers are also shown in decimals if the decimals of called token is known.
+func printCallData(data []byte, decimals int, indent string, depth int) {
+	if len(data) < 4 {
+		return
+	}
+	entries := sigRegistry.lookupMethod(data[:4])
+	if len(entries) == 0 {
+		log.Printf("%vunknown method %v", indent, hexutil.Encode(data[:4]))
+		return
+	}
+	if len(entries) > 1 {
+		log.Printf("%vselector %v has %v candidates", indent, hexutil.Encode(data[:4]), len(entries))
+	}
+	for _, entry := range entries {
+		log.Printf("%vthe tx is calling method => %v (%v)", indent, entry.Signature, entry.Source)
+		method, err := entry.abiMethod()
+		if err != nil {
+			log.Warn("parse method signature failed", "signature", entry.Signature, "err", err)
+			continue
+		}
+		values, err := method.Inputs.UnpackValues(data[4:])
+		if err != nil {
+			log.Warn("decode call data failed", "method", entry.Signature, "err", err)
+			continue
+		}
+		for i, arg := range method.Inputs {
+			name := arg.Name
+			if name == "" {
+				name = fmt.Sprintf("arg%d", i)
+			}
+			printABIValue(indent+"  ", name, arg.Type, reflect.ValueOf(values[i]), decimals, depth)
+		}
+	}
+}
+
+func printABIValue(indent, name string, typ abi.Type, value reflect.Value, decimals, depth int) {
+	switch typ.T {
+	case abi.TupleTy:
+		log.Printf("%v%v (%v):", indent, name, typ.String())
+		for i, elem := range typ.TupleElems {
+			printABIValue(indent+"  ", typ.TupleRawNames[i], *elem, value.Field(i), decimals, depth)
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		log.Printf("%v%v (%v): %v elements", indent, name, typ.String(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			printABIValue(indent+"  ", fmt.Sprintf("[%d]", i), *typ.Elem, value.Index(i), decimals, depth)
+		}
+	case abi.BytesTy:
+		data := value.Bytes()
+		log.Printf("%v%v (%v): %v", indent, name, typ.String(), hexutil.Encode(data))
+		// the contract called by nested call data is unknown
+		if depth < maxCallDataDepth && len(data) >= 4 && len(sigRegistry.lookupMethod(data[:4])) > 0 {
+			printCallData(data, unknownDecimals, indent+"  ", depth+1)
+		}
+	default:
+		log.Printf("%v%v (%v): %v", indent, name, typ.String(), formatABIArgument(typ, value.Interface(), decimals))
+	}
+}
+
+func formatABIArgument(typ abi.Type, value interface{}, decimals int) string {
+	switch typ.T {
+	case abi.AddressTy:
+		return value.(common.Address).String()
+	case abi.FixedBytesTy:
+		v := reflect.ValueOf(value)
+		data := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(data), v)
+		return hexutil.Encode(data)
+	case abi.StringTy:
+		return fmt.Sprintf("%q", value)
+	case abi.UintTy:
+		bi, ok := abiValueToBigInt(value)
+		if ok && decimals != unknownDecimals {
+			return fmt.Sprintf("%v (%v in token decimals %v)", bi, formatTokenAmount(bi, decimals), decimals)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// formatTokenAmount formats amount in decimals, eg. 1500000000000000000 is 1.5 in 18 decimals
+func formatTokenAmount(amount *big.Int, decimals int) string {
+	str := amount.String()
+	if len(str) <= decimals {
+		str = strings.Repeat("0", decimals-len(str)+1) + str
+	}
+	integer, fraction := str[:len(str)-decimals], strings.TrimRight(str[len(str)-decimals:], "0")
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKnownMethodSignatures(t *testing.T) {
+	for selector, sig := range knownContractMethods {
+		method, err := parseMethodSignature(sig)
+		if assert.Nil(t, err, sig) {
+			assert.Equal(t, selector, hex.EncodeToString(method.ID), sig)
+		}
+	}
+}
+
+func TestDecodeTupleCallData(t *testing.T) {
+	entries := sigRegistry.lookupMethod(common.FromHex("0x8fef8489"))
+	assert.Equal(t, 1, len(entries))
+	method, err := entries[0].abiMethod()
+	assert.Nil(t, err)
+
+	type swapInfo struct {
+		Arg0 [32]byte
+		Arg1 common.Address
+		Arg2 common.Address
+		Arg3 *big.Int
+		Arg4 *big.Int
+	}
+	info := swapInfo{
+		Arg0: common.HexToHash("0x01"),
+		Arg1: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Arg2: common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		Arg3: big.NewInt(1000),
+		Arg4: big.NewInt(56),
+	}
+	input, err := method.Inputs.Pack("swapID", info)
+	assert.Nil(t, err)
+
+	values, err := method.Inputs.UnpackValues(input)
+	assert.Nil(t, err)
+	assert.Equal(t, "swapID", values[0])
+	printCallData(append(common.CopyBytes(method.ID), input...), unknownDecimals, "", 0)
+}
+
+func TestFormatTokenAmount(t *testing.T) {
+	amount, _ := new(big.Int).SetString("1500000000000000000", 10)
+	assert.Equal(t, "1.5", formatTokenAmount(amount, 18))
+	assert.Equal(t, "0.000001", formatTokenAmount(big.NewInt(1e12), 18))
+	assert.Equal(t, "2", formatTokenAmount(big.NewInt(2e6), 6))
+
+	// amounts are only shown in decimals of known token
+	typ, err := abi.NewType("uint256", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "2500000", formatABIArgument(typ, big.NewInt(2500000), unknownDecimals))
+	assert.Equal(t, "2500000 (2.5 in token decimals 6)", formatABIArgument(typ, big.NewInt(2500000), 6))
+}
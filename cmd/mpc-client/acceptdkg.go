@@ -31,7 +31,7 @@ func acceptDKG(ctx *cli.Context) (err error) {
 }
 
 func getDKGInfoByKeyID(keyID string) (dkgInfo *mpcrpc.ReqAddrInfoData, err error) {
-	dkgInfos, err := mpcrpc.GetCurNodeReqAddrInfo(0)
+	dkgInfos, err := mpcClient.GetCurNodeReqAddrInfoContext(bgCtx, 0)
 	if err != nil {
 		log.Error("getCurNodeReqAddrInfo failed", "err", err)
 		return nil, err
@@ -67,7 +67,7 @@ func doAcceptDKGNoninteractively(keyID, agreeResult string) (err error) {
 		return doAcceptDKG(keyID, agreeResult)
 	}
 
-	dkgInfos, err := mpcrpc.GetCurNodeReqAddrInfo(0)
+	dkgInfos, err := mpcClient.GetCurNodeReqAddrInfoContext(bgCtx, 0)
 	if err != nil {
 		log.Error("getCurNodeReqAddrInfo failed", "err", err)
 		return err
@@ -85,7 +85,7 @@ func doAcceptDKGNoninteractively(keyID, agreeResult string) (err error) {
 }
 
 func doAcceptDKG(keyID, agreeResult string) (err error) {
-	result, err := mpcrpc.DoAcceptReqAddr(keyID, agreeResult)
+	result, err := mpcClient.DoAcceptReqAddrContext(bgCtx, keyID, agreeResult)
 	if err != nil {
 		log.Error("mpc accept dkg failed", "keyID", keyID, "rpcResult", result, "err", err)
 		return err
@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/anyswap/mpc-client/cmd/utils"
 	"github.com/anyswap/mpc-client/log"
 	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/urfave/cli/v2"
@@ -29,6 +33,10 @@ var (
 			nonInteractiveFlag,
 			agreeSignFlag,
 			disagreeSignFlag,
+			journalFlag,
+			abiFilesFlag,
+			tokenDecimalsFlag,
+			signatureDirFlag,
 			mpcServerFlag,
 			mpcKeystoreFlag,
 			mpcPasswordFlag,
@@ -68,6 +76,16 @@ func acceptSign(ctx *cli.Context) (err error) {
 		return acceptDKG(ctx)
 	}
 
+	err = initAcceptJournal(ctx)
+	if err != nil {
+		return err
+	}
+	defer acceptJournal.close()
+	err = loadSignatureRegistry(ctx)
+	if err != nil {
+		return err
+	}
+
 	if !interactiveMode {
 		isAgree := ctx.Bool(agreeSignFlag.Name) && !ctx.Bool(disagreeSignFlag.Name) // disagree first
 		agreeResult := getAgreeResult(isAgree)
@@ -83,16 +101,16 @@ func acceptSign(ctx *cli.Context) (err error) {
 		return errors.New("empty message context")
 	}
 
-	// verify message context
-	msgContextType := signInfo.MsgContext[0]
-	switch strings.ToLower(msgContextType) {
-	case "ethtx":
-		err = verifyEthTxSignInfo(signInfo)
-	case "plaintext":
-		err = verifyPlainTextSignInfo(signInfo)
-	default:
-		err = fmt.Errorf("unknown message context type")
+	if decided := acceptJournal.getDecision(keyID); decided != nil {
+		log.Warn("sign is already decided", "keyID", keyID, "decision", decided.Decision,
+			"time", time.Unix(decided.Time, 0).Format(time.RFC3339), "rpcResult", decided.RPCResult)
+		if !askForReply("Do you still want to continue?") {
+			return nil
+		}
 	}
+
+	// verify message context
+	err = verifySignInfo(signInfo)
 	if err != nil {
 		log.Error("message context is unresolvable", "err", err)
 		log.Info("please check the above message context manually.")
@@ -104,7 +122,34 @@ func acceptSign(ctx *cli.Context) (err error) {
 
 	isAgree := askForReply("Do you agree this sign?")
 	agreeResult := getAgreeResult(isAgree)
-	return doAcceptSign(keyID, agreeResult, signInfo.MsgHash, signInfo.MsgContext)
+	return doAcceptSign(keyID, agreeResult, "interactive", signInfo.MsgHash, signInfo.MsgContext)
+}
+
+func verifySignInfo(signInfo *mpcrpc.SignInfoData) error {
+	if len(signInfo.MsgContext) == 0 {
+		return errors.New("empty message context")
+	}
+	msgContextType := signInfo.MsgContext[0]
+	switch strings.ToLower(msgContextType) {
+	case "ethtx":
+		return verifyEthTxSignInfo(signInfo)
+	case "replacetx":
+		return verifyReplaceTxSignInfo(signInfo)
+	case "withdrawfee":
+		return verifyEthTxSignInfo(signInfo)
+	case "plaintext":
+		return verifyPlainTextSignInfo(signInfo)
+	case "eip712":
+		return verifyTypedDataSignInfo(signInfo)
+	case "personalsign":
+		return verifyPersonalSignInfo(signInfo)
+	case "btctx":
+		return verifyBtcTxSignInfo(signInfo)
+	case "batch":
+		return verifyBatchSignInfo(signInfo)
+	default:
+		return fmt.Errorf("unknown message context type")
+	}
 }
 
 func askForReply(prompt string) bool {
@@ -139,17 +184,69 @@ func verifyEthTxSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
 		return fmt.Errorf("json unmarshal msgContext to ethtx failed. %w", err)
 	}
 
-	log.Printf("the sign is sending the following tx to block chain (chainID: %v)", chainIDStr)
+	if rawTx.Type() != types.LegacyTxType && rawTx.ChainId().Cmp(chainID) != 0 {
+		return fmt.Errorf("tx chainID %v mismatch with context chainID %v", rawTx.ChainId(), chainID)
+	}
+
+	log.Printf("the sign is sending the following tx to block chain (chainID: %v, tx type: %v)", chainIDStr, rawTx.Type())
 	if errf := printTx(&rawTx, true); errf != nil {
 		log.Warn("print transaction failed", "err", errf)
 	}
 	parseEthTx(&rawTx)
 
-	chainSigner := types.NewEIP155Signer(chainID)
+	// the signer hashes the tx according to its type
+	chainSigner := types.LatestSignerForChainID(chainID)
 	calcedHash := chainSigner.Hash(&rawTx)
 	return checkMessageHash(calcedHash, msgHash)
 }
 
+// verifyReplaceTxSignInfo message context is
+// [replacetx, txJSON, chainID, origTxHash, origTxJSON, mode, memo(optional)],
+// the signed original tx is verified to be sent by the mpc address.
+func verifyReplaceTxSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
+	msgContexts := signInfo.MsgContext
+	if len(msgContexts) < 6 {
+		return errors.New("wrong message context length, must have at least six elements")
+	}
+	chainID, ok := new(big.Int).SetString(msgContexts[2], 0)
+	if !ok {
+		return fmt.Errorf("wrong block chainID '%v'", msgContexts[2])
+	}
+	origTxHash := msgContexts[3]
+	if !strings.EqualFold(common.HexToHash(origTxHash).String(), origTxHash) {
+		return fmt.Errorf("wrong original tx hash '%v'", origTxHash)
+	}
+	var origTx, rawTx types.Transaction
+	if err = json.Unmarshal([]byte(msgContexts[4]), &origTx); err != nil {
+		return fmt.Errorf("json unmarshal original tx failed. %w", err)
+	}
+	if origTx.Hash() != common.HexToHash(origTxHash) {
+		return fmt.Errorf("original tx hash %v mismatch with %v", origTx.Hash().String(), origTxHash)
+	}
+	if err = json.Unmarshal([]byte(msgContexts[1]), &rawTx); err != nil {
+		return fmt.Errorf("json unmarshal msgContext to ethtx failed. %w", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), &origTx)
+	if err != nil {
+		return fmt.Errorf("get sender of original tx failed. %w", err)
+	}
+	pubkeyInfo, err := mpcrpc.GetPublicKeyInfo(mpcrpc.KeyTypeECDSA, signInfo.PubKey)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(pubkeyInfo.EVMAddress, sender.String()) {
+		return fmt.Errorf("original tx sender %v mismatch with mpc address %v", sender.String(), pubkeyInfo.EVMAddress)
+	}
+
+	mode := msgContexts[5]
+	log.Printf("the sign is replacing the pending tx %v (%v), the differences are", origTxHash, mode)
+	printReplaceTxDiff(&origTx, &rawTx)
+	if err = verifyReplaceTx(&origTx, &rawTx, sender, mode); err != nil {
+		return err
+	}
+	return verifyEthTxSignInfo(signInfo)
+}
+
 func verifyPlainTextSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
 	msgHashes := signInfo.MsgHash
 	msgContexts := signInfo.MsgContext
@@ -165,6 +262,109 @@ func verifyPlainTextSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
 	return checkMessageHash(calcedHash, msgHash)
 }
 
+func verifyTypedDataSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
+	msgHashes := signInfo.MsgHash
+	msgContexts := signInfo.MsgContext
+	if len(msgHashes) != 1 {
+		return errors.New("wrong message hash length, must have exact one element")
+	}
+	if len(msgContexts) < 2 {
+		return errors.New("wrong message context length, must have at least two elements")
+	}
+	td, err := parseTypedData([]byte(msgContexts[1]))
+	if err != nil {
+		return err
+	}
+	log.Printf("the sign is signing the following eip712 typed data")
+	fmt.Print(td.format())
+	calcedHash, err := td.hash()
+	if err != nil {
+		return err
+	}
+	return checkMessageHash(calcedHash, msgHashes[0])
+}
+
+func verifyPersonalSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
+	msgHashes := signInfo.MsgHash
+	msgContexts := signInfo.MsgContext
+	if len(msgHashes) != 1 {
+		return errors.New("wrong message hash length, must have exact one element")
+	}
+	if len(msgContexts) < 2 {
+		return errors.New("wrong message context length, must have at least two elements")
+	}
+	message, err := hexutil.Decode(msgContexts[1])
+	if err != nil {
+		return fmt.Errorf("wrong personal sign message '%v', %w", msgContexts[1], err)
+	}
+	if utf8.Valid(message) {
+		log.Printf("the sign is signing the following personal message\n%s", message)
+	} else {
+		log.Printf("the sign is signing the following personal message of hex string\n%v", msgContexts[1])
+	}
+	calcedHash := common.BytesToHash(accounts.TextHash(message))
+	return checkMessageHash(calcedHash, msgHashes[0])
+}
+
+// verifyBtcTxSignInfo recompute the sighashes of all inputs,
+// the signer address is derived from the public key of the sign.
+// legacy input amounts are verified by the previous txs in context.
+func verifyBtcTxSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
+	msgHashes := signInfo.MsgHash
+	msgContexts := signInfo.MsgContext
+	if len(msgContexts) < 2 {
+		return errors.New("wrong message context length, must have at least two elements")
+	}
+	var btcContext btcTxContext
+	err = json.Unmarshal([]byte(msgContexts[1]), &btcContext)
+	if err != nil {
+		return fmt.Errorf("json unmarshal msgContext to btctx failed. %w", err)
+	}
+	signer, err := newBtcSigner(btcContext.Network, btcContext.AddressType, signInfo.PubKey)
+	if err != nil {
+		return err
+	}
+	tx, err := decodeBtcTx(btcContext.RawTx)
+	if err != nil {
+		return err
+	}
+	log.Printf("the sign is sending the following tx to %v network (txid: %v)", btcContext.Network, tx.TxHash())
+	signer.printBtcTx(tx, btcContext.Amounts)
+	if err = signer.checkPrevTxs(tx, btcContext.Amounts, btcContext.PrevTxs); err != nil {
+		return err
+	}
+
+	calcedHashes, err := signer.sigHashes(tx, btcContext.Amounts)
+	if err != nil {
+		return err
+	}
+	if len(calcedHashes) != len(msgHashes) {
+		return fmt.Errorf("sighash count %v mismatch with message hash count %v", len(calcedHashes), len(msgHashes))
+	}
+	for i, calcedHash := range calcedHashes {
+		if err = checkMessageHash(common.HexToHash(calcedHash), msgHashes[i]); err != nil {
+			return fmt.Errorf("check sighash of input %v failed, %w", i, err)
+		}
+	}
+	return nil
+}
+
+// verifyBatchSignInfo verify every entry of batch sign individually,
+// msgContext[1] is the json array of the message hashes and contexts of entries.
+func verifyBatchSignInfo(signInfo *mpcrpc.SignInfoData) (err error) {
+	entries, err := parseBatchEntries(signInfo)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		log.Printf("verify batch sign entry %v/%v, msgHashes are %v", i+1, len(entries), entry.MsgHash)
+		if err = verifySignInfo(entry); err != nil {
+			return fmt.Errorf("verify batch sign entry %v failed, %w", i+1, err)
+		}
+	}
+	return nil
+}
+
 func checkMessageHash(calcedHash common.Hash, msgHash string) error {
 	if calcedHash == common.HexToHash(msgHash) {
 		return nil
@@ -173,7 +373,7 @@ func checkMessageHash(calcedHash common.Hash, msgHash string) error {
 }
 
 func getSignInfoByKeyID(keyID string) (signInfo *mpcrpc.SignInfoData, err error) {
-	signInfos, err := mpcrpc.GetCurNodeSignInfo(0)
+	signInfos, err := mpcClient.GetCurNodeSignInfoContext(bgCtx, 0)
 	if err != nil {
 		log.Error("getCurNodeSignInfo failed", "err", err)
 		return nil, err
@@ -209,34 +409,43 @@ func getAgreeResult(isAgree bool) string {
 	return "DISAGREE"
 }
 
+// doAcceptSignNoninteractively skips the signs decided in journal,
+// and accepts all signs one by one to journal every decision before exiting.
 func doAcceptSignNoninteractively(keyID, agreeResult string) (err error) {
 	if !isAllKeyID(keyID) {
+		if decided := acceptJournal.getDecision(keyID); decided != nil {
+			log.Warn("skip decided sign info", "keyID", keyID, "decision", decided.Decision)
+			return nil
+		}
 		signInfo, errt := getSignInfoByKeyID(keyID)
 		if errt != nil {
 			return errt
 		}
-		return doAcceptSign(keyID, agreeResult, signInfo.MsgHash, signInfo.MsgContext)
+		return doAcceptSign(keyID, agreeResult, "non-interactive", signInfo.MsgHash, signInfo.MsgContext)
 	}
 
-	signInfos, err := mpcrpc.GetCurNodeSignInfo(0)
+	signInfos, err := mpcClient.GetCurNodeSignInfoContext(bgCtx, 0)
 	if err != nil {
 		log.Error("getCurNodeSignInfo failed", "err", err)
 		return err
 	}
 
-	for _, info := range signInfos {
-		go func(signInfo *mpcrpc.SignInfoData) {
-			errt := doAcceptSign(signInfo.Key, agreeResult, signInfo.MsgHash, signInfo.MsgContext)
-			if errt != nil {
-				log.Warn("accept sign failed", "signInfo", signInfo, "agreeResult", agreeResult, "err", err)
-			}
-		}(info)
+	for _, signInfo := range signInfos {
+		if decided := acceptJournal.getDecision(signInfo.Key); decided != nil {
+			log.Info("skip decided sign info", "keyID", signInfo.Key, "decision", decided.Decision)
+			continue
+		}
+		errt := doAcceptSign(signInfo.Key, agreeResult, "non-interactive", signInfo.MsgHash, signInfo.MsgContext)
+		if errt != nil {
+			log.Warn("accept sign failed", "keyID", signInfo.Key, "agreeResult", agreeResult, "err", errt)
+		}
 	}
 	return nil
 }
 
-func doAcceptSign(keyID, agreeResult string, msgHashes, msgContexts []string) (err error) {
-	result, err := mpcrpc.DoAcceptSign(keyID, agreeResult, msgHashes, msgContexts)
+func doAcceptSign(keyID, agreeResult, reason string, msgHashes, msgContexts []string) (err error) {
+	result, err := mpcClient.DoAcceptSignContext(bgCtx, keyID, agreeResult, msgHashes, msgContexts)
+	recordAcceptSign(keyID, agreeResult, reason, msgHashes, msgContexts, result, err)
 	if err != nil {
 		log.Error("mpc accept sign failed", "keyID", keyID, "rpcResult", result, "err", err)
 		return err
@@ -29,6 +29,7 @@ var (
 			initiatorAddrFlag,
 			receiversAddrFlag,
 			multicallsAddrFlag,
+			journalFlag,
 			mpcServerFlag,
 			mpcKeystoreFlag,
 			mpcPasswordFlag,
@@ -84,15 +85,23 @@ func acceptWithdrawFee(ctx *cli.Context) (err error) {
 	}
 	log.Infof("withdraw fee allowed multicall contracts are %v", allowedMulticallAddrs)
 
+	err = initAcceptJournal(ctx)
+	if err != nil {
+		return err
+	}
+	defer acceptJournal.close()
+
 	var loop uint64
 	for {
 		loop++
 		log.Infof("start accept loop %v", loop)
 
-		signInfos, errf := mpcrpc.GetCurNodeSignInfo(0)
+		signInfos, errf := mpcClient.GetCurNodeSignInfoContext(bgCtx, 0)
 		if errf != nil {
 			log.Error("getCurNodeSignInfo failed", "err", errf)
-			time.Sleep(5 * time.Second)
+			if !sleepOrCanceled(5 * time.Second) {
+				return bgCtx.Err()
+			}
 			continue
 		}
 
@@ -100,6 +109,10 @@ func acceptWithdrawFee(ctx *cli.Context) (err error) {
 
 		for _, info := range signInfos {
 			keyID := info.Key
+			if decided := acceptJournal.getDecision(keyID); decided != nil {
+				log.Debug("skip decided sign info", "keyID", keyID, "decision", decided.Decision)
+				continue
+			}
 
 			isAgree, isIgnore, errf := verifyWithdrawFeeSignInfo(info)
 			if isIgnore {
@@ -110,13 +123,19 @@ func acceptWithdrawFee(ctx *cli.Context) (err error) {
 				log.Warn("diagree sign info", "keyID", keyID, "err", errf)
 			}
 
+			var reason string
+			if errf != nil {
+				reason = errf.Error()
+			}
 			agreeResult := getAgreeResult(isAgree)
-			errf = doAcceptSign(keyID, agreeResult, info.MsgHash, info.MsgContext)
+			errf = doAcceptSign(keyID, agreeResult, reason, info.MsgHash, info.MsgContext)
 			if errf != nil {
 				log.Warn("call accept sign error", "keyID", keyID, "err", errf)
 			}
 		}
-		time.Sleep(5 * time.Second)
+		if !sleepOrCanceled(5 * time.Second) {
+			return bgCtx.Err()
+		}
 	}
 }
 
@@ -171,7 +190,11 @@ func verifyWithdrawFeeSignInfo(info *mpcrpc.SignInfoData) (isAgree, isIgnore boo
 		return isAgree, isIgnore, fmt.Errorf("json unmarshal msgContext failed. %w", err)
 	}
 
-	log.Printf("the sign is sending the following tx to block chain (chainID: %v)", chainIDStr)
+	if rawTx.Type() != types.LegacyTxType && rawTx.ChainId().Cmp(chainID) != 0 {
+		return isAgree, isIgnore, fmt.Errorf("tx chainID %v mismatch with context chainID %v", rawTx.ChainId(), chainID)
+	}
+
+	log.Printf("the sign is sending the following tx to block chain (chainID: %v, tx type: %v)", chainIDStr, rawTx.Type())
 	if errf := printTx(&rawTx, true); errf != nil {
 		log.Warn("print transaction failed", "err", errf)
 	}
@@ -184,7 +207,8 @@ func verifyWithdrawFeeSignInfo(info *mpcrpc.SignInfoData) (isAgree, isIgnore boo
 	// the sign info message context is right, will not ignore it from now on
 	isIgnore = false
 
-	chainSigner := types.NewEIP155Signer(chainID)
+	// the signer hashes the tx according to its type
+	chainSigner := types.LatestSignerForChainID(chainID)
 	calcedHash := chainSigner.Hash(&rawTx)
 	err = checkMessageHash(calcedHash, msgHash)
 	if err != nil {
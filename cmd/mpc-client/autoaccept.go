@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/anyswap/mpc-client/mpcrpc/client"
+	"github.com/urfave/cli/v2"
+)
+
+const alertTimeout = 10 // seconds
+
+var (
+	autoAcceptCommand = &cli.Command{
+		Action:    autoAccept,
+		Name:      "autoaccept",
+		Usage:     "start accepting signs automatically by policy",
+		ArgsUsage: "",
+		Description: `
+every sign info in the accept list is evaluated by the policy rules,
+and is agreed, disagreed or ignored according to the matched rule.
+the agreed signs are recorded in the limit store, and signs exceeding
+the value or rate limits of policy are disagreed and alerted, the alerts
+are recorded in journal and posted to the alert webhook.`,
+		Flags: []cli.Flag{
+			policyFileFlag,
+			limitStoreFlag,
+			alertWebhookFlag,
+			journalFlag,
+			abiFilesFlag,
+			tokenDecimalsFlag,
+			signatureDirFlag,
+			dryrunFlag,
+			mpcServerFlag,
+			mpcKeystoreFlag,
+			mpcPasswordFlag,
+			apiPrefixFlag,
+			rpcTimeoutFlag,
+		},
+	}
+)
+
+func autoAccept(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	mpcCfg.NeedKeyStore = true
+	err = checkAndInitMpcConfig(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	policyFile := ctx.String(policyFileFlag.Name)
+	if policyFile == "" {
+		return errors.New("must specify policy file (with --policy option)")
+	}
+	policy, err := loadSignPolicy(policyFile)
+	if err != nil {
+		return err
+	}
+	log.Info("load sign policy success", "rules", len(policy.Rules), "default", policy.Default)
+	dryrun := ctx.Bool(dryrunFlag.Name)
+
+	err = initAcceptJournal(ctx)
+	if err != nil {
+		return err
+	}
+	defer acceptJournal.close()
+	err = loadSignatureRegistry(ctx)
+	if err != nil {
+		return err
+	}
+
+	var store *limitStore
+	if policy.hasLimits() {
+		store, err = loadLimitStore(ctx.String(limitStoreFlag.Name))
+		if err != nil {
+			return err
+		}
+		log.Info("load limit store success", "file", store.file, "records", len(store.Records),
+			"limits", len(policy.Limits), "maxAgreesPerHour", policy.MaxAgreesPerHour)
+	}
+
+	alertWebhook := ctx.String(alertWebhookFlag.Name)
+
+	// ignored sign infos are kept in accept list, log them only once
+	ignored := make(map[string]struct{})
+	alerted := make(map[string]struct{})
+
+	var loop uint64
+	for {
+		loop++
+		log.Infof("start accept loop %v", loop)
+
+		signInfos, errf := mpcClient.GetCurNodeSignInfoContext(bgCtx, 0)
+		if errf != nil {
+			log.Error("getCurNodeSignInfo failed", "err", errf)
+			if !sleepOrCanceled(5 * time.Second) {
+				return bgCtx.Err()
+			}
+			continue
+		}
+
+		log.Infof("loop %v, count in accept list is %v", loop, len(signInfos))
+
+		for _, info := range signInfos {
+			keyID := info.Key
+			if _, exist := ignored[keyID]; exist {
+				continue
+			}
+			if decided := acceptJournal.getDecision(keyID); decided != nil {
+				log.Debug("skip decided sign info", "keyID", keyID, "decision", decided.Decision)
+				continue
+			}
+
+			decision := policy.evaluate(info)
+			if decision.Outcome == policyAgree && store != nil {
+				errf = applyLimits(policy, store, keyID, decision, dryrun)
+				if errf != nil {
+					log.Error("check sign policy limits failed, refuse to agree", "keyID", keyID, "rule", decision.Rule, "err", errf)
+					decision.Outcome = policyDisagree
+					decision.Reason = errf.Error()
+					if _, exist := alerted[keyID]; !exist && !dryrun {
+						alertLimitRefused(alertWebhook, info, decision)
+						alerted[keyID] = struct{}{}
+					}
+				}
+			}
+			if decision.Outcome == policyIgnore {
+				log.Info("ignore sign info", "keyID", keyID, "rule", decision.Rule, "reason", decision.Reason)
+				ignored[keyID] = struct{}{}
+				continue
+			}
+			isAgree := decision.Outcome == policyAgree
+			log.Info("sign policy decision", "keyID", keyID, "outcome", decision.Outcome, "rule", decision.Rule, "reason", decision.Reason)
+			if dryrun {
+				ignored[keyID] = struct{}{}
+				continue
+			}
+
+			reason := "rule: " + decision.Rule
+			if decision.Reason != "" {
+				reason += ", " + decision.Reason
+			}
+			errf = doAcceptSign(keyID, getAgreeResult(isAgree), reason, info.MsgHash, info.MsgContext)
+			if errf != nil {
+				log.Warn("call accept sign error", "keyID", keyID, "err", errf)
+			}
+		}
+		if !sleepOrCanceled(5 * time.Second) {
+			return bgCtx.Err()
+		}
+	}
+}
+
+// limitAlert is posted to the alert webhook if limits refuse to agree,
+// it may be caused by a leaked initiator key or a wrong policy.
+type limitAlert struct {
+	Time       int64    `json:"time"`
+	KeyID      string   `json:"keyID"`
+	Rule       string   `json:"rule"`
+	Reason     string   `json:"reason"`
+	MsgContext []string `json:"msgContext"`
+}
+
+// alertLimitRefused records the alert in journal and posts it to webhook
+func alertLimitRefused(webhook string, info *mpcrpc.SignInfoData, decision *policyDecision) {
+	alert := &limitAlert{
+		Time:       time.Now().Unix(),
+		KeyID:      info.Key,
+		Rule:       decision.Rule,
+		Reason:     decision.Reason,
+		MsgContext: info.MsgContext,
+	}
+	recordLimitAlert(info.Key, "rule: "+decision.Rule+", "+decision.Reason, info.MsgHash, info.MsgContext)
+	if webhook == "" {
+		return
+	}
+	resp, err := client.HTTPPostWithContext(bgCtx, webhook, alert, nil, nil, alertTimeout)
+	if err == nil {
+		_ = resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			err = &client.HTTPStatusError{URL: webhook, StatusCode: resp.StatusCode}
+		}
+	}
+	if err != nil {
+		log.Error("post limit alert failed", "keyID", info.Key, "webhook", webhook, "err", err)
+		return
+	}
+	log.Info("post limit alert success", "keyID", info.Key)
+}
+
+// applyLimits checks the limits and records the agreed sign before accepting
+func applyLimits(policy *signPolicy, store *limitStore, keyID string, decision *policyDecision, dryrun bool) error {
+	now := time.Now()
+	rec, err := policy.checkLimits(store, keyID, decision.context, now)
+	if err != nil || dryrun {
+		return err
+	}
+	return store.add(rec, now.Add(-policy.maxPeriod()).Unix())
+}
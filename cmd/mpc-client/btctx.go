@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// bitcoin-family address types of mpc public key
+const (
+	btcAddressTypeP2PKH  = "p2pkh"
+	btcAddressTypeP2WPKH = "p2wpkh"
+
+	btcDustAmount = 546
+)
+
+var (
+	errUnknownBtcNetwork     = errors.New("unknown bitcoin-family network")
+	errUnknownBtcAddressType = errors.New("unknown bitcoin-family address type")
+
+	litecoinParams = chaincfg.Params{
+		Name:             "litecoin",
+		Net:              0xdbb6c0fb,
+		Bech32HRPSegwit:  "ltc",
+		PubKeyHashAddrID: 0x30,
+		ScriptHashAddrID: 0x32,
+	}
+
+	dogecoinParams = chaincfg.Params{
+		Name:             "dogecoin",
+		Net:              0xc0c0c0c0,
+		PubKeyHashAddrID: 0x1e,
+		ScriptHashAddrID: 0x16,
+	}
+
+	btcNetworks = map[string]*chaincfg.Params{
+		"btc":         &chaincfg.MainNetParams,
+		"btc-testnet": &chaincfg.TestNet3Params,
+		"ltc":         &litecoinParams,
+		"doge":        &dogecoinParams,
+	}
+)
+
+func init() {
+	// register to decode addresses of these networks
+	for _, params := range []*chaincfg.Params{&litecoinParams, &dogecoinParams} {
+		if err := chaincfg.Register(params); err != nil {
+			panic(fmt.Sprintf("register %v network failed, %v", params.Name, err))
+		}
+	}
+}
+
+// btcTxContext message context of bitcoin-family tx,
+// amounts are the values of the spent outputs of inputs in order,
+// prev txs are the raw txs of the spent outputs to verify legacy input amounts.
+type btcTxContext struct {
+	Network     string   `json:"network"`
+	AddressType string   `json:"addressType"`
+	RawTx       string   `json:"rawTx"`
+	Amounts     []int64  `json:"amounts"`
+	PrevTxs     []string `json:"prevTxs,omitempty"`
+}
+
+// btcSigner spends the outputs paid to the address of mpc public key,
+// the compressed public key is used to derive the address.
+type btcSigner struct {
+	params   *chaincfg.Params
+	addrType string
+	pubkey   []byte
+	address  btcutil.Address
+	pkScript []byte
+}
+
+func newBtcSigner(network, addrType, mpcPubkey string) (*btcSigner, error) {
+	params, exist := btcNetworks[network]
+	if !exist {
+		return nil, fmt.Errorf("%w '%v'", errUnknownBtcNetwork, network)
+	}
+	pk, err := btcec.ParsePubKey(common.FromHex(mpcPubkey), btcec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("wrong mpc public key, %w", err)
+	}
+	pubkey := pk.SerializeCompressed()
+	pubkeyHash := btcutil.Hash160(pubkey)
+
+	var address btcutil.Address
+	switch addrType {
+	case btcAddressTypeP2PKH:
+		address, err = btcutil.NewAddressPubKeyHash(pubkeyHash, params)
+	case btcAddressTypeP2WPKH:
+		if params.Bech32HRPSegwit == "" {
+			return nil, fmt.Errorf("network %v does not support segwit", network)
+		}
+		address, err = btcutil.NewAddressWitnessPubKeyHash(pubkeyHash, params)
+	default:
+		return nil, fmt.Errorf("%w '%v'", errUnknownBtcAddressType, addrType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, err
+	}
+	return &btcSigner{
+		params:   params,
+		addrType: addrType,
+		pubkey:   pubkey,
+		address:  address,
+		pkScript: pkScript,
+	}, nil
+}
+
+func (s *btcSigner) isWitness() bool {
+	return s.addrType == btcAddressTypeP2WPKH
+}
+
+// sigHashes calc the SIGHASH_ALL hashes of all inputs of tx,
+// segwit (BIP143) sighash commits to the input amounts, legacy does not.
+func (s *btcSigner) sigHashes(tx *wire.MsgTx, amounts []int64) ([]string, error) {
+	if len(amounts) != len(tx.TxIn) {
+		return nil, fmt.Errorf("input amount count %v mismatch with input count %v", len(amounts), len(tx.TxIn))
+	}
+	var witnessSigHashes *txscript.TxSigHashes
+	if s.isWitness() {
+		witnessSigHashes = txscript.NewTxSigHashes(tx)
+	}
+	hashes := make([]string, len(tx.TxIn))
+	for i := range tx.TxIn {
+		var hash []byte
+		var err error
+		if s.isWitness() {
+			hash, err = txscript.CalcWitnessSigHash(s.pkScript, witnessSigHashes, txscript.SigHashAll, tx, i, amounts[i])
+		} else {
+			hash, err = txscript.CalcSignatureHash(s.pkScript, txscript.SigHashAll, tx, i)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("calc sighash of input %v failed, %w", i, err)
+		}
+		hashes[i] = common.BytesToHash(hash).String()
+	}
+	return hashes, nil
+}
+
+// checkPrevTxs checks the spent outputs in the previous raw txs are paid to the signer
+// with the input amounts, it's required by legacy sighash which does not commit to amounts.
+func (s *btcSigner) checkPrevTxs(tx *wire.MsgTx, amounts []int64, prevTxs []string) error {
+	if len(prevTxs) != len(tx.TxIn) {
+		if !s.isWitness() {
+			return fmt.Errorf("previous tx count %v mismatch with input count %v, legacy input amounts can not be verified", len(prevTxs), len(tx.TxIn))
+		}
+		if len(prevTxs) != 0 {
+			return fmt.Errorf("previous tx count %v mismatch with input count %v", len(prevTxs), len(tx.TxIn))
+		}
+		return nil
+	}
+	if len(amounts) != len(tx.TxIn) {
+		return fmt.Errorf("input amount count %v mismatch with input count %v", len(amounts), len(tx.TxIn))
+	}
+	for i, txIn := range tx.TxIn {
+		prevTx, err := decodeBtcTx(prevTxs[i])
+		if err != nil {
+			return fmt.Errorf("wrong previous tx of input %v, %w", i, err)
+		}
+		outPoint := txIn.PreviousOutPoint
+		if prevTx.TxHash() != outPoint.Hash {
+			return fmt.Errorf("previous tx %v mismatch with input %v spending %v", prevTx.TxHash(), i, outPoint.String())
+		}
+		if int(outPoint.Index) >= len(prevTx.TxOut) {
+			return fmt.Errorf("input %v spends nonexistent output %v", i, outPoint.String())
+		}
+		prevOut := prevTx.TxOut[outPoint.Index]
+		if !bytes.Equal(prevOut.PkScript, s.pkScript) {
+			return fmt.Errorf("input %v spends output %v not paid to %v", i, outPoint.String(), s.address.EncodeAddress())
+		}
+		if prevOut.Value != amounts[i] {
+			return fmt.Errorf("input %v amount %v mismatch with output value %v", i, amounts[i], prevOut.Value)
+		}
+	}
+	return nil
+}
+
+// applySignatures fills the signature script or witness of every input
+// by DER encoding the mpc signature rsv of its sighash.
+func (s *btcSigner) applySignatures(tx *wire.MsgTx, rsvs []string) error {
+	if len(rsvs) != len(tx.TxIn) {
+		return fmt.Errorf("rsv count %v mismatch with input count %v", len(rsvs), len(tx.TxIn))
+	}
+	for i, rsv := range rsvs {
+		rsvBytes := common.FromHex(rsv)
+		if len(rsvBytes) != crypto.SignatureLength {
+			return fmt.Errorf("wrong rsv length of input %v", i)
+		}
+		sig := &btcec.Signature{
+			R: new(big.Int).SetBytes(rsvBytes[:32]),
+			S: new(big.Int).SetBytes(rsvBytes[32:64]),
+		}
+		// Serialize canonicalizes S to the lower half order
+		sigBytes := append(sig.Serialize(), byte(txscript.SigHashAll))
+		if s.isWitness() {
+			tx.TxIn[i].Witness = wire.TxWitness{sigBytes, s.pubkey}
+			continue
+		}
+		sigScript, err := txscript.NewScriptBuilder().AddData(sigBytes).AddData(s.pubkey).Script()
+		if err != nil {
+			return err
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	return nil
+}
+
+// verifyScripts executes the scripts of all inputs of the signed tx
+func (s *btcSigner) verifyScripts(tx *wire.MsgTx, amounts []int64) error {
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for i := range tx.TxIn {
+		vm, err := txscript.NewEngine(s.pkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, amounts[i])
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return fmt.Errorf("verify script of input %v failed, %w", i, err)
+		}
+	}
+	return nil
+}
+
+func encodeBtcTx(tx *wire.MsgTx) (string, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+func decodeBtcTx(rawTx string) (*wire.MsgTx, error) {
+	data, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("wrong raw tx, %w", err)
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err = tx.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("wrong raw tx, %w", err)
+	}
+	if len(tx.TxIn) == 0 || len(tx.TxOut) == 0 {
+		return nil, errors.New("wrong raw tx, no inputs or outputs")
+	}
+	return tx, nil
+}
+
+// printBtcTx prints inputs, outputs and fee, outputs to the signer are marked as change.
+func (s *btcSigner) printBtcTx(tx *wire.MsgTx, amounts []int64) {
+	var totalIn, totalOut int64
+	fmt.Printf("from %v (%v)\n", s.address.EncodeAddress(), s.addrType)
+	for i, txIn := range tx.TxIn {
+		var amount int64
+		if i < len(amounts) {
+			amount = amounts[i]
+		}
+		totalIn += amount
+		fmt.Printf("input %v: %v, amount %v\n", i, txIn.PreviousOutPoint.String(), amount)
+	}
+	for i, txOut := range tx.TxOut {
+		totalOut += txOut.Value
+		receiver := hex.EncodeToString(txOut.PkScript)
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, s.params)
+		if err == nil && len(addrs) == 1 {
+			receiver = addrs[0].EncodeAddress()
+		}
+		if bytes.Equal(txOut.PkScript, s.pkScript) {
+			receiver += " (change)"
+		}
+		fmt.Printf("output %v: %v, value %v\n", i, receiver, txOut.Value)
+	}
+	fmt.Printf("fee is %v\n", totalIn-totalOut)
+}
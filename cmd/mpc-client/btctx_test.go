@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBtcSignerSignAndVerify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	pubkey := hexutil.Encode(crypto.FromECDSAPub(&key.PublicKey))
+
+	for _, addrType := range []string{btcAddressTypeP2PKH, btcAddressTypeP2WPKH} {
+		signer, err := newBtcSigner("btc-testnet", addrType, pubkey)
+		assert.Nil(t, err)
+
+		tx := wire.NewMsgTx(wire.TxVersion)
+		amounts := []int64{100000, 200000}
+		for i := range amounts {
+			tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{byte(i + 1)}, uint32(i)), nil, nil))
+		}
+		tx.AddTxOut(wire.NewTxOut(250000, signer.pkScript))
+
+		hashes, err := signer.sigHashes(tx, amounts)
+		assert.Nil(t, err)
+		rsvs := make([]string, len(hashes))
+		for i, hash := range hashes {
+			sig, errs := crypto.Sign(common.HexToHash(hash).Bytes(), key)
+			assert.Nil(t, errs)
+			rsvs[i] = hexutil.Encode(sig)
+		}
+
+		assert.Nil(t, signer.applySignatures(tx, rsvs))
+		assert.Nil(t, signer.verifyScripts(tx, amounts), addrType)
+
+		if signer.isWitness() {
+			// segwit sighash commits to the input amounts
+			assert.NotNil(t, signer.verifyScripts(tx, []int64{100001, 200000}))
+		}
+	}
+
+	_, err = newBtcSigner("doge", btcAddressTypeP2WPKH, pubkey)
+	assert.NotNil(t, err)
+}
+
+func TestBtcSignerCheckPrevTxs(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	signer, err := newBtcSigner("btc-testnet", btcAddressTypeP2PKH, hexutil.Encode(crypto.FromECDSAPub(&key.PublicKey)))
+	assert.Nil(t, err)
+
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(50000, []byte{txscript.OP_TRUE}))
+	prevTx.AddTxOut(wire.NewTxOut(100000, signer.pkScript))
+	rawPrevTx, err := encodeBtcTx(prevTx)
+	assert.Nil(t, err)
+	prevHash := prevTx.TxHash()
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 1), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(90000, signer.pkScript))
+
+	assert.Nil(t, signer.checkPrevTxs(tx, []int64{100000}, []string{rawPrevTx}))
+	// legacy input amounts must be verified
+	assert.NotNil(t, signer.checkPrevTxs(tx, []int64{100000}, nil))
+	assert.NotNil(t, signer.checkPrevTxs(tx, []int64{1000000}, []string{rawPrevTx}))
+
+	// output not paid to signer
+	tx.TxIn[0].PreviousOutPoint.Index = 0
+	assert.NotNil(t, signer.checkPrevTxs(tx, []int64{50000}, []string{rawPrevTx}))
+}
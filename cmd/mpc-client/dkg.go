@@ -5,8 +5,6 @@ import (
 
 	"github.com/anyswap/mpc-client/cmd/utils"
 	"github.com/anyswap/mpc-client/log"
-	"github.com/anyswap/mpc-client/mpcrpc"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 )
 
@@ -21,6 +19,7 @@ var (
 			gidFlag,
 			thresholdFlag,
 			signModeFlag,
+			signTypeFlag,
 			enodeSigsFlag,
 			mpcServerFlag,
 			mpcKeystoreFlag,
@@ -42,17 +41,12 @@ func doDKG(ctx *cli.Context) (err error) {
 	}
 
 	enodeSigs := ctx.StringSlice(enodeSigsFlag.Name)
-	keyID, pubkey, err := mpcrpc.DoDKG(enodeSigs)
+	keyID, pubkey, err := mpcClient.DoDKGContext(bgCtx, enodeSigs)
 	if err != nil {
 		log.Error("mpc dkg failed", "err", err)
 		return err
 	}
-	log.Info("mpc dkg success", "keyID", keyID)
-
-	pkBytes := common.FromHex(pubkey)
-	if len(pkBytes) != 65 || pkBytes[0] != 4 {
-		return fmt.Errorf("wrong mpc public key '%v'", pubkey)
-	}
+	log.Info("mpc dkg success", "keyID", keyID, "keytype", mpcClient.SignType())
 
 	fmt.Println("pubkey is", pubkey)
 	return nil
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EIP-712 typed structured data hashing, see https://eips.ethereum.org/EIPS/eip-712
+
+const (
+	eip712DomainType  = "EIP712Domain"
+	maxTypedDataDepth = 32
+)
+
+var (
+	errWrongTypedData = errors.New("wrong eip712 typed data")
+
+	typedDataArrayRegexp = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+)
+
+type typedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type typedData struct {
+	Types       map[string][]typedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+// parseTypedData parse eip712 json document, numbers are kept
+// as json.Number to avoid losing precision of big integers.
+func parseTypedData(data []byte) (*typedData, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var td typedData
+	if err := dec.Decode(&td); err != nil {
+		return nil, fmt.Errorf("%w, %v", errWrongTypedData, err)
+	}
+	if _, exist := td.Types[eip712DomainType]; !exist {
+		return nil, fmt.Errorf("%w, missing type %v", errWrongTypedData, eip712DomainType)
+	}
+	if _, exist := td.Types[td.PrimaryType]; !exist || td.PrimaryType == eip712DomainType {
+		return nil, fmt.Errorf("%w, wrong primary type '%v'", errWrongTypedData, td.PrimaryType)
+	}
+	if td.Domain == nil || td.Message == nil {
+		return nil, fmt.Errorf("%w, missing domain or message", errWrongTypedData)
+	}
+	return &td, nil
+}
+
+// hash returns keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+func (td *typedData) hash() (common.Hash, error) {
+	domainSeparator, err := td.hashStruct(eip712DomainType, td.Domain, 0)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("hash domain failed, %w", err)
+	}
+	messageHash, err := td.hashStruct(td.PrimaryType, td.Message, 0)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("hash message failed, %w", err)
+	}
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
+
+func (td *typedData) hashStruct(typeName string, data map[string]interface{}, depth int) ([]byte, error) {
+	if depth > maxTypedDataDepth {
+		return nil, fmt.Errorf("%w, exceed max depth %v", errWrongTypedData, maxTypedDataDepth)
+	}
+	fields := td.Types[typeName]
+	if len(data) > len(fields) {
+		return nil, fmt.Errorf("%w, extra fields in data of type %v", errWrongTypedData, typeName)
+	}
+	buf := crypto.Keccak256([]byte(td.encodeType(typeName)))
+	for _, field := range fields {
+		value, exist := data[field.Name]
+		if !exist {
+			return nil, fmt.Errorf("%w, missing field '%v' of type %v", errWrongTypedData, field.Name, typeName)
+		}
+		enc, err := td.encodeValue(field.Type, value, depth)
+		if err != nil {
+			return nil, fmt.Errorf("encode field '%v' of type %v failed, %w", field.Name, typeName, err)
+		}
+		buf = append(buf, enc...)
+	}
+	return crypto.Keccak256(buf), nil
+}
+
+// encodeType returns the type encoding, the referenced struct types
+// are sorted by name and appended after the type itself.
+func (td *typedData) encodeType(typeName string) string {
+	deps := td.dependencies(typeName, nil)
+	sort.Strings(deps[1:])
+	var sb strings.Builder
+	for _, dep := range deps {
+		sb.WriteString(dep)
+		sb.WriteString("(")
+		for i, field := range td.Types[dep] {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(field.Type)
+			sb.WriteString(" ")
+			sb.WriteString(field.Name)
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+func (td *typedData) dependencies(typeName string, found []string) []string {
+	if idx := strings.Index(typeName, "["); idx >= 0 {
+		typeName = typeName[:idx]
+	}
+	if _, exist := td.Types[typeName]; !exist {
+		return found
+	}
+	for _, name := range found {
+		if name == typeName {
+			return found
+		}
+	}
+	found = append(found, typeName)
+	for _, field := range td.Types[typeName] {
+		found = td.dependencies(field.Type, found)
+	}
+	return found
+}
+
+func (td *typedData) encodeValue(typ string, value interface{}, depth int) ([]byte, error) {
+	if match := typedDataArrayRegexp.FindStringSubmatch(typ); match != nil {
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w, value of type %v is not array", errWrongTypedData, typ)
+		}
+		if match[2] != "" {
+			if length, _ := strconv.Atoi(match[2]); length != len(array) {
+				return nil, fmt.Errorf("%w, array length %v mismatch with type %v", errWrongTypedData, len(array), typ)
+			}
+		}
+		var buf []byte
+		for _, elem := range array {
+			enc, err := td.encodeValue(match[1], elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, enc...)
+		}
+		return crypto.Keccak256(buf), nil
+	}
+	if _, exist := td.Types[typ]; exist {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w, value of type %v is not struct", errWrongTypedData, typ)
+		}
+		return td.hashStruct(typ, data, depth+1)
+	}
+	return encodeTypedDataAtomic(typ, value)
+}
+
+func encodeTypedDataAtomic(typ string, value interface{}) ([]byte, error) {
+	switch {
+	case typ == "string":
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w, value %v is not string", errWrongTypedData, value)
+		}
+		return crypto.Keccak256([]byte(str)), nil
+	case typ == "bytes":
+		data, err := parseTypedDataBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(data), nil
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w, value %v is not bool", errWrongTypedData, value)
+		}
+		if b {
+			return math.U256Bytes(big.NewInt(1)), nil
+		}
+		return make([]byte, 32), nil
+	case typ == "address":
+		str, ok := value.(string)
+		if !ok || !common.IsHexAddress(str) {
+			return nil, fmt.Errorf("%w, value %v is not address", errWrongTypedData, value)
+		}
+		return common.LeftPadBytes(common.HexToAddress(str).Bytes(), 32), nil
+	case strings.HasPrefix(typ, "bytes"):
+		length, err := strconv.Atoi(typ[len("bytes"):])
+		if err != nil || length < 1 || length > 32 {
+			return nil, fmt.Errorf("%w, unknown type %v", errWrongTypedData, typ)
+		}
+		data, err := parseTypedDataBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != length {
+			return nil, fmt.Errorf("%w, value %v is not %v", errWrongTypedData, value, typ)
+		}
+		return common.RightPadBytes(data, 32), nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		bi, err := parseTypedDataInteger(typ, value)
+		if err != nil {
+			return nil, err
+		}
+		return math.U256Bytes(bi), nil
+	default:
+		return nil, fmt.Errorf("%w, unknown type %v", errWrongTypedData, typ)
+	}
+}
+
+func parseTypedDataBytes(value interface{}) ([]byte, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w, value %v is not hex string", errWrongTypedData, value)
+	}
+	data, err := hexutil.Decode(str)
+	if err != nil {
+		return nil, fmt.Errorf("%w, value %v is not hex string", errWrongTypedData, value)
+	}
+	return data, nil
+}
+
+// parseTypedDataInteger parse integer of json number, decimal or hex string,
+// and check it's in the range of the integer type.
+func parseTypedDataInteger(typ string, value interface{}) (*big.Int, error) {
+	signed := strings.HasPrefix(typ, "int")
+	bits := 256
+	if bitsStr := strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"); bitsStr != "" {
+		var err error
+		bits, err = strconv.Atoi(bitsStr)
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("%w, unknown type %v", errWrongTypedData, typ)
+		}
+	}
+	var bi *big.Int
+	var ok bool
+	switch v := value.(type) {
+	case json.Number:
+		bi, ok = new(big.Int).SetString(string(v), 10)
+	case string:
+		bi, ok = new(big.Int).SetString(v, 0)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w, value %v is not %v", errWrongTypedData, value, typ)
+	}
+	if signed {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		if bi.Cmp(new(big.Int).Neg(limit)) < 0 || bi.Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("%w, value %v overflows %v", errWrongTypedData, value, typ)
+		}
+	} else if bi.Sign() < 0 || bi.BitLen() > bits {
+		return nil, fmt.Errorf("%w, value %v overflows %v", errWrongTypedData, value, typ)
+	}
+	return bi, nil
+}
+
+// format returns a human readable representation of the domain and message
+func (td *typedData) format() string {
+	var sb strings.Builder
+	sb.WriteString("domain:\n")
+	td.formatStruct(&sb, eip712DomainType, td.Domain, 1)
+	fmt.Fprintf(&sb, "primary type: %v\n", td.PrimaryType)
+	td.formatStruct(&sb, td.PrimaryType, td.Message, 1)
+	return sb.String()
+}
+
+func (td *typedData) formatStruct(sb *strings.Builder, typeName string, data map[string]interface{}, indent int) {
+	for _, field := range td.Types[typeName] {
+		td.formatValue(sb, field.Name, field.Type, data[field.Name], indent)
+	}
+}
+
+func (td *typedData) formatValue(sb *strings.Builder, name, typ string, value interface{}, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	if match := typedDataArrayRegexp.FindStringSubmatch(typ); match != nil {
+		array, _ := value.([]interface{})
+		fmt.Fprintf(sb, "%v%v (%v):\n", prefix, name, typ)
+		for i, elem := range array {
+			td.formatValue(sb, fmt.Sprintf("[%d]", i), match[1], elem, indent+1)
+		}
+		return
+	}
+	if _, exist := td.Types[typ]; exist {
+		data, _ := value.(map[string]interface{})
+		fmt.Fprintf(sb, "%v%v (%v):\n", prefix, name, typ)
+		td.formatStruct(sb, typ, data, indent+1)
+		return
+	}
+	if str, ok := value.(string); ok && typ == "address" && common.IsHexAddress(str) {
+		value = common.HexToAddress(str).String()
+	}
+	fmt.Fprintf(sb, "%v%v (%v): %v\n", prefix, name, typ, value)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the example of https://eips.ethereum.org/EIPS/eip-712
+const mailTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestTypedDataHash(t *testing.T) {
+	td, err := parseTypedData([]byte(mailTypedData))
+	assert.Nil(t, err)
+	assert.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", td.encodeType("Mail"))
+
+	hash, err := td.hash()
+	assert.Nil(t, err)
+	assert.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hash.String())
+
+	td.Message["contents"] = 1
+	_, err = td.hash()
+	assert.True(t, errors.Is(err, errWrongTypedData))
+
+	delete(td.Message, "contents")
+	_, err = td.hash()
+	assert.True(t, errors.Is(err, errWrongTypedData))
+}
+
+func TestParseTypedDataInteger(t *testing.T) {
+	_, err := parseTypedDataInteger("uint8", "256")
+	assert.NotNil(t, err)
+	_, err = parseTypedDataInteger("int8", "-129")
+	assert.NotNil(t, err)
+	bi, err := parseTypedDataInteger("int8", "-128")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-128), bi.Int64())
+	bi, err = parseTypedDataInteger("uint256", "0xff")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(255), bi.Int64())
+}
@@ -54,7 +54,7 @@ var (
 	}
 	signTypeFlag = &cli.StringFlag{
 		Name:  "keytype",
-		Usage: "mpc sign algorithm type",
+		Usage: "mpc sign algorithm type (ECDSA or ED25519)",
 		Value: "ECDSA",
 	}
 	gidFlag = &cli.StringFlag{
@@ -138,12 +138,41 @@ var (
 	}
 	gasLimitFlag = &cli.Uint64Flag{
 		Name:  "gas",
-		Usage: "tx gas limit",
-		Value: 90000,
+		Usage: "tx gas limit (estimated by gateways if not specified)",
 	}
 	gasPriceFlag = &cli.StringFlag{
 		Name:  "gasPrice",
-		Usage: "tx gas price in Wei",
+		Usage: "tx gas price in Wei (suggested by gateways if not specified)",
+	}
+	gasLimitMultiplierFlag = &cli.Float64Flag{
+		Name:  "gasLimitMultiplier",
+		Usage: "multiplier of estimated gas limit",
+		Value: 1.2,
+	}
+	gasPriceMultiplierFlag = &cli.Float64Flag{
+		Name:  "gasPriceMultiplier",
+		Usage: "multiplier of suggested gas price or priority fee",
+		Value: 1.0,
+	}
+	maxGasFeeFlag = &cli.StringFlag{
+		Name:  "maxGasFee",
+		Usage: "safety cap of gas price or max fee per gas in Wei",
+	}
+	maxTotalFeeFlag = &cli.StringFlag{
+		Name:  "maxTotalFee",
+		Usage: "safety cap of total fee (gas limit * gas price or max fee per gas) in Wei",
+	}
+	maxFeePerGasFlag = &cli.StringFlag{
+		Name:  "maxFeePerGas",
+		Usage: "EIP-1559 tx max fee per gas in Wei (conflict with gasPrice)",
+	}
+	maxPriorityFeePerGasFlag = &cli.StringFlag{
+		Name:  "maxPriorityFeePerGas",
+		Usage: "EIP-1559 tx max priority fee per gas in Wei",
+	}
+	accessListFlag = &cli.StringFlag{
+		Name:  "accessList",
+		Usage: "EIP-2930 tx access list json file",
 	}
 	nonceFlag = &cli.StringFlag{
 		Name:  "nonce",
@@ -153,8 +182,117 @@ var (
 		Name:  "value",
 		Usage: "tx value of native coins",
 	}
+	btcNetworkFlag = &cli.StringFlag{
+		Name:  "btcNet",
+		Usage: "bitcoin-family network (btc, btc-testnet, ltc, doge)",
+		Value: "btc",
+	}
+	btcNetworksFlag = &cli.StringSliceFlag{
+		Name:  "btcNet",
+		Usage: "bitcoin-family networks to show addresses (multiple)",
+		Value: cli.NewStringSlice("btc"),
+	}
+	btcAddressTypeFlag = &cli.StringFlag{
+		Name:  "addrType",
+		Usage: "bitcoin-family address type of mpc public key (p2pkh or p2wpkh)",
+		Value: "p2wpkh",
+	}
+	utxosFlag = &cli.StringFlag{
+		Name:  "utxos",
+		Usage: "json file of utxos to spend [{txid, vout, amount, rawTx(optional)}]",
+	}
+	btcFeeFlag = &cli.Int64Flag{
+		Name:  "fee",
+		Usage: "bitcoin-family tx fee of satoshis",
+	}
+	txHashFlag = &cli.StringFlag{
+		Name:  "txHash",
+		Usage: "hash of the pending tx to be replaced",
+	}
+	bumpPercentFlag = &cli.Uint64Flag{
+		Name:  "bumpPercent",
+		Usage: "percent to bump the fee of the replaced tx",
+		Value: 10,
+	}
+	batchFileFlag = &cli.StringFlag{
+		Name:  "batchFile",
+		Usage: "json file of batch sign entries [{msgHash or msgHashes, msgContext}]",
+	}
+	maxBatchSizeFlag = &cli.Uint64Flag{
+		Name:  "maxBatchSize",
+		Usage: "max number of message hashes in one mpc sign request",
+		Value: 20,
+	}
+	typedDataFlag = &cli.StringFlag{
+		Name:  "typedData",
+		Usage: "eip712 typed data json file",
+	}
+	txFileFlag = &cli.StringFlag{
+		Name:  "txFile",
+		Usage: "tx file (unsigned tx file for signtx, signed raw tx file for broadcasttx)",
+	}
+	rawTxFlag = &cli.StringFlag{
+		Name:  "rawTx",
+		Usage: "signed raw tx of hex string",
+	}
+	outputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "output file (default to stdout)",
+	}
+	policyFileFlag = &cli.StringFlag{
+		Name:  "policy",
+		Usage: "sign policy file (toml, or json if with .json extension)",
+	}
+	limitStoreFlag = &cli.StringFlag{
+		Name:  "limitStore",
+		Usage: "file to persist agreed signs for the limits of sign policy",
+		Value: "autoaccept-limits.json",
+	}
+	alertWebhookFlag = &cli.StringFlag{
+		Name:  "alertWebhook",
+		Usage: "url to post json alert when sign policy limits refuse to agree",
+	}
+	journalFlag = &cli.StringFlag{
+		Name:  "journal",
+		Usage: "journal file to record accept sign decisions (disabled if not specified)",
+	}
+	abiFilesFlag = &cli.StringSliceFlag{
+		Name:  "abi",
+		Usage: "abi json files to decode tx call data (can be specified multiple times)",
+	}
+	tokenDecimalsFlag = &cli.StringSliceFlag{
+		Name:  "tokenDecimals",
+		Usage: "token decimals to show amounts in call data, eg. 0x...:6 (can be specified multiple times)",
+	}
+	signatureDirFlag = &cli.StringFlag{
+		Name:  "sigDir",
+		Usage: "directory of abi json files (*.json) and signature list files to decode tx call data",
+	}
+	lookupFlag = &cli.StringSliceFlag{
+		Name:  "selector",
+		Usage: "4 bytes method selector, 32 bytes event topic, or signature to look up (can be specified multiple times)",
+	}
+	historyCountFlag = &cli.Uint64Flag{
+		Name:  "count",
+		Usage: "number of the latest entries to show (0 to show all)",
+		Value: 20,
+	}
 	dryrunFlag = &cli.BoolFlag{
 		Name:  "dryrun",
 		Usage: "dry run",
 	}
+	waitFlag = &cli.BoolFlag{
+		Name:  "wait",
+		Usage: "wait tx receipt after sending tx",
+	}
+	confirmationsFlag = &cli.Uint64Flag{
+		Name:  "confirmations",
+		Usage: "wait until tx is confirmed by this number of blocks",
+		Value: 1,
+	}
+	waitTimeoutFlag = &cli.Uint64Flag{
+		Name:  "waitTimeout",
+		Usage: "wait tx receipt timeout of seconds",
+		Value: 300,
+	}
 )
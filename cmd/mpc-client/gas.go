@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+var errNoEIP1559Support = errors.New("chain does not support EIP-1559 (no base fee in latest block)")
+
+type gasOptionArgs struct {
+	gasLimitMultiplier float64
+	gasPriceMultiplier float64
+	maxGasFee          *big.Int
+	maxTotalFee        *big.Int
+}
+
+var gasOptions gasOptionArgs
+
+func checkGasOptionArguments(ctx *cli.Context) (err error) {
+	gasOptions.gasLimitMultiplier = ctx.Float64(gasLimitMultiplierFlag.Name)
+	if gasOptions.gasLimitMultiplier < 1 {
+		return fmt.Errorf("gas limit multiplier %v is less than 1", gasOptions.gasLimitMultiplier)
+	}
+	gasOptions.gasPriceMultiplier = ctx.Float64(gasPriceMultiplierFlag.Name)
+	if gasOptions.gasPriceMultiplier <= 0 {
+		return fmt.Errorf("gas price multiplier %v is not positive", gasOptions.gasPriceMultiplier)
+	}
+	gasOptions.maxGasFee, err = parseBigIntArgument("max gas fee", ctx.String(maxGasFeeFlag.Name))
+	if err != nil {
+		return err
+	}
+	gasOptions.maxTotalFee, err = parseBigIntArgument("max total fee", ctx.String(maxTotalFeeFlag.Name))
+	return err
+}
+
+// fillGasArguments fills the gas limit and fees which are not specified
+// by the suggestion of gateways, and checks the fees do not exceed the cap.
+// If no fee is specified, dynamic fee tx is used on EIP-1559 chains.
+func fillGasArguments() (err error) {
+	if txArgs.gasPrice == nil {
+		err = fillFeeArguments()
+		if err != nil {
+			return err
+		}
+	}
+	if txArgs.gasLimit == 0 {
+		gasLimit, errf := estimateGas()
+		if errf != nil {
+			log.Error("estimate gas failed", "err", errf)
+			return errf
+		}
+		txArgs.gasLimit = uint64(float64(gasLimit) * gasOptions.gasLimitMultiplier)
+		log.Info("estimate gas success", "estimated", gasLimit, "gasLimit", txArgs.gasLimit)
+	}
+	return checkMaxGasFee()
+}
+
+func fillFeeArguments() error {
+	var baseFee *big.Int
+	var err error
+	if txArgs.maxFeePerGas == nil && txArgs.maxPriorityFeePerGas == nil {
+		baseFee, err = getLatestBaseFee()
+		if err != nil {
+			return err
+		}
+		if baseFee == nil {
+			gasPrice, errf := suggestGasPrice()
+			if errf != nil {
+				log.Error("suggest gas price failed", "err", errf)
+				return errf
+			}
+			txArgs.gasPrice = multiplyBigInt(gasPrice, gasOptions.gasPriceMultiplier)
+			log.Info("suggest gas price success", "suggested", gasPrice, "gasPrice", txArgs.gasPrice)
+			return nil
+		}
+	}
+
+	if txArgs.maxPriorityFeePerGas == nil {
+		gasTipCap, errf := suggestGasTipCap()
+		if errf != nil {
+			log.Error("suggest gas tip cap failed", "err", errf)
+			return errf
+		}
+		txArgs.maxPriorityFeePerGas = multiplyBigInt(gasTipCap, gasOptions.gasPriceMultiplier)
+		if txArgs.maxFeePerGas != nil && txArgs.maxPriorityFeePerGas.Cmp(txArgs.maxFeePerGas) > 0 {
+			txArgs.maxPriorityFeePerGas = new(big.Int).Set(txArgs.maxFeePerGas)
+		}
+		log.Info("suggest gas tip cap success", "suggested", gasTipCap, "maxPriorityFeePerGas", txArgs.maxPriorityFeePerGas)
+	}
+	if txArgs.maxFeePerGas == nil {
+		if baseFee == nil {
+			baseFee, err = getLatestBaseFee()
+			if err != nil {
+				return err
+			}
+			if baseFee == nil {
+				return errNoEIP1559Support
+			}
+		}
+		// same as go-ethereum, tolerate the base fee doubling
+		txArgs.maxFeePerGas = new(big.Int).Add(txArgs.maxPriorityFeePerGas, new(big.Int).Mul(baseFee, big.NewInt(2)))
+		log.Info("calc max fee per gas success", "baseFee", baseFee, "maxFeePerGas", txArgs.maxFeePerGas)
+	}
+	return nil
+}
+
+// checkMaxGasFee checks the per gas price and the total fee do not exceed the caps
+func checkMaxGasFee() error {
+	feeCap := txArgs.gasPrice
+	if feeCap == nil {
+		feeCap = txArgs.maxFeePerGas
+	}
+	if feeCap == nil {
+		return nil
+	}
+	if gasOptions.maxGasFee != nil && feeCap.Cmp(gasOptions.maxGasFee) > 0 {
+		return fmt.Errorf("gas price or max fee per gas %v exceeds the max gas fee %v", feeCap, gasOptions.maxGasFee)
+	}
+	if gasOptions.maxTotalFee != nil {
+		totalFee := new(big.Int).Mul(feeCap, new(big.Int).SetUint64(txArgs.gasLimit))
+		if totalFee.Cmp(gasOptions.maxTotalFee) > 0 {
+			return fmt.Errorf("total fee %v (gas limit %v) exceeds the max total fee %v", totalFee, txArgs.gasLimit, gasOptions.maxTotalFee)
+		}
+	}
+	return nil
+}
+
+func multiplyBigInt(bi *big.Int, multiplier float64) *big.Int {
+	if multiplier == 1 {
+		return bi
+	}
+	result, _ := new(big.Float).Mul(new(big.Float).SetInt(bi), big.NewFloat(multiplier)).Int(nil)
+	return result
+}
+
+func medianBigInt(values []*big.Int) *big.Int {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Cmp(values[j]) < 0
+	})
+	return values[len(values)/2]
+}
+
+// getLatestBaseFee returns the max base fee of the latest blocks of gateways,
+// returns nil if the chain does not support EIP-1559.
+func getLatestBaseFee() (maxBaseFee *big.Int, err error) {
+	var success bool
+	for _, ethClient := range ethClients {
+		header, errf := ethClient.cli.HeaderByNumber(bgCtx, nil)
+		if errf != nil {
+			log.Warn("get latest header failed", "url", ethClient.url, "err", errf)
+			err = errf
+			continue
+		}
+		success = true
+		if header.BaseFee != nil && (maxBaseFee == nil || header.BaseFee.Cmp(maxBaseFee) > 0) {
+			maxBaseFee = header.BaseFee
+		}
+	}
+	if success {
+		return maxBaseFee, nil
+	}
+	return nil, err
+}
+
+// suggestGasPrice returns the median of suggested gas prices of gateways
+func suggestGasPrice() (*big.Int, error) {
+	var prices []*big.Int
+	var err error
+	for _, ethClient := range ethClients {
+		price, errf := ethClient.cli.SuggestGasPrice(bgCtx)
+		if errf != nil {
+			log.Warn("suggest gas price failed", "url", ethClient.url, "err", errf)
+			err = errf
+			continue
+		}
+		prices = append(prices, price)
+	}
+	if len(prices) == 0 {
+		return nil, err
+	}
+	return medianBigInt(prices), nil
+}
+
+// suggestGasTipCap returns the median of suggested gas tip caps of gateways
+func suggestGasTipCap() (*big.Int, error) {
+	var tips []*big.Int
+	var err error
+	for _, ethClient := range ethClients {
+		tip, errf := ethClient.cli.SuggestGasTipCap(bgCtx)
+		if errf != nil {
+			log.Warn("suggest gas tip cap failed", "url", ethClient.url, "err", errf)
+			err = errf
+			continue
+		}
+		tips = append(tips, tip)
+	}
+	if len(tips) == 0 {
+		return nil, err
+	}
+	return medianBigInt(tips), nil
+}
+
+// estimateGas returns the max of estimated gas of gateways
+func estimateGas() (maxGas uint64, err error) {
+	var to *common.Address
+	if !txArgs.createContract {
+		to = &txArgs.to
+	}
+	msg := ethereum.CallMsg{
+		From:       txArgs.from,
+		To:         to,
+		Value:      txArgs.value,
+		Data:       txArgs.input,
+		AccessList: txArgs.accessList,
+	}
+	var success bool
+	for _, ethClient := range ethClients {
+		gas, errf := ethClient.cli.EstimateGas(bgCtx, msg)
+		if errf != nil {
+			log.Warn("estimate gas failed", "url", ethClient.url, "err", errf)
+			err = errf
+			continue
+		}
+		success = true
+		if gas > maxGas {
+			maxGas = gas
+		}
+	}
+	if success {
+		return maxGas, nil
+	}
+	return 0, err
+}
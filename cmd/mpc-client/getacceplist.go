@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/anyswap/mpc-client/cmd/utils"
-	"github.com/anyswap/mpc-client/mpcrpc"
 	"github.com/urfave/cli/v2"
 )
 
@@ -38,7 +37,7 @@ func getAcceptList(ctx *cli.Context) (err error) {
 	isDKG := ctx.Bool(mpcDKGFlag.Name)
 	expiredInterval := ctx.Int64(expiredIntervalFlag.Name)
 	if isDKG {
-		accpetList, err := mpcrpc.GetDKGAcceptList(user, expiredInterval)
+		accpetList, err := mpcClient.GetDKGAcceptListContext(bgCtx, user, expiredInterval)
 		if err != nil {
 			return err
 		}
@@ -49,7 +48,7 @@ func getAcceptList(ctx *cli.Context) (err error) {
 		fmt.Println(string(jsData))
 		fmt.Println("accept list length is", len(accpetList))
 	} else {
-		accpetList, err := mpcrpc.GetAcceptList(user, expiredInterval)
+		accpetList, err := mpcClient.GetAcceptListContext(bgCtx, user, expiredInterval)
 		if err != nil {
 			return err
 		}
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/anyswap/mpc-client/cmd/utils"
-	"github.com/anyswap/mpc-client/mpcrpc"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/urfave/cli/v2"
 )
@@ -37,7 +36,7 @@ func getEnode(ctx *cli.Context) (err error) {
 		return err
 	}
 
-	enode, err := mpcrpc.GetEnode(mpcCfg.RPCAddress)
+	enode, err := mpcClient.GetEnodeContext(bgCtx, mpcClient.RPCAddress())
 	if err != nil {
 		return err
 	}
@@ -53,7 +52,7 @@ func getEnode(ctx *cli.Context) (err error) {
 		return fmt.Errorf("wrong enode '%v'", enode)
 	}
 	enodePubkey := enode[startIndex+8 : endIndex]
-	sig, err := mpcrpc.SignContent([]byte(enodePubkey))
+	sig, err := mpcClient.SignContent([]byte(enodePubkey))
 	if err != nil {
 		return err
 	}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/anyswap/mpc-client/cmd/utils"
-	"github.com/anyswap/mpc-client/mpcrpc"
 	"github.com/urfave/cli/v2"
 )
 
@@ -33,7 +32,7 @@ func getGroup(ctx *cli.Context) (err error) {
 	}
 
 	groupID := ctx.String(groupIDFlag.Name)
-	groupInfo, err := mpcrpc.GetGroupByID(groupID, mpcCfg.RPCAddress)
+	groupInfo, err := mpcClient.GetGroupByIDContext(bgCtx, groupID, "")
 	if err != nil {
 		return err
 	}
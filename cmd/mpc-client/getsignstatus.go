@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/anyswap/mpc-client/cmd/utils"
-	"github.com/anyswap/mpc-client/mpcrpc"
 	"github.com/urfave/cli/v2"
 )
 
@@ -36,7 +35,7 @@ func getSignStatus(ctx *cli.Context) (err error) {
 	keyID := ctx.String(keyIDFlag.Name)
 	isDKG := ctx.Bool(mpcDKGFlag.Name)
 	if isDKG {
-		dkgStatus, err := mpcrpc.GetReqAddrStatus(keyID, mpcCfg.RPCAddress)
+		dkgStatus, err := mpcClient.GetReqAddrStatusContext(bgCtx, keyID, "")
 		if err != nil {
 			return err
 		}
@@ -47,7 +46,7 @@ func getSignStatus(ctx *cli.Context) (err error) {
 		}
 		fmt.Println(string(jsData))
 	} else {
-		signStatus, err := mpcrpc.GetSignStatus(keyID, mpcCfg.RPCAddress)
+		signStatus, err := mpcClient.GetSignStatusContext(bgCtx, keyID, "")
 		if err != nil {
 			return err
 		}
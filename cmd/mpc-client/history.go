@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	historyCommand = &cli.Command{
+		Action:    showHistory,
+		Name:      "history",
+		Usage:     "show accept sign decisions in journal",
+		ArgsUsage: "",
+		Description: `
+show the latest 'count' decisions of accept sign commands,
+or all the decisions of keyID in detail if 'key' is specified.`,
+		Flags: []cli.Flag{
+			journalFlag,
+			keyIDFlag,
+			historyCountFlag,
+		},
+	}
+)
+
+func showHistory(ctx *cli.Context) error {
+	utils.SetLogger(ctx)
+	journalFile := ctx.String(journalFlag.Name)
+	if journalFile == "" {
+		return errors.New("must specify journal file")
+	}
+	j, err := openJournal(journalFile, false)
+	if err != nil {
+		return err
+	}
+
+	keyID := ctx.String(keyIDFlag.Name)
+	if keyID != "" {
+		var found bool
+		for _, entry := range j.entries {
+			if !strings.EqualFold(entry.KeyID, keyID) {
+				continue
+			}
+			found = true
+			jsData, errf := json.MarshalIndent(entry, "", "  ")
+			if errf != nil {
+				return errf
+			}
+			fmt.Println(string(jsData))
+		}
+		if !found {
+			return fmt.Errorf("keyID %v not found in journal", keyID)
+		}
+		return nil
+	}
+
+	entries := j.entries
+	if count := int(ctx.Uint64(historyCountFlag.Name)); count > 0 && count < len(entries) {
+		entries = entries[len(entries)-count:]
+	}
+	for _, entry := range entries {
+		result := entry.RPCResult
+		if entry.Error != "" {
+			result = "error: " + entry.Error
+		}
+		fmt.Printf("%v %v %v %v %v result: %v reason: %v\n",
+			time.Unix(entry.Time, 0).Format(time.RFC3339), entry.KeyID, entry.Decision,
+			entry.Decoded["type"], formatDecodedTx(entry.Decoded), result, entry.Reason)
+	}
+	return nil
+}
+
+func formatDecodedTx(decoded map[string]string) string {
+	if decoded["chainID"] == "" {
+		return "-"
+	}
+	s := fmt.Sprintf("chainID %v to %v value %v", decoded["chainID"], decoded["to"], decoded["value"])
+	if method := decoded["method"]; method != "" {
+		s += " method " + method
+	}
+	return s
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -21,7 +22,8 @@ var (
 	msgContextArg  string
 	signMemoArg    string
 
-	mpcCfg mpcrpc.MPCConfig
+	mpcCfg    mpcrpc.MPCConfig
+	mpcClient *mpcrpc.Client
 )
 
 func checkAndInitMpcConfig(ctx *cli.Context, isSign bool) (err error) {
@@ -68,7 +70,21 @@ func checkAndInitMpcConfig(ctx *cli.Context, isSign bool) (err error) {
 
 	mergeConfigFromConfigFile(ctx)
 
-	mpcrpc.Init(&mpcCfg, isSign)
+	mpcClient, err = mpcrpc.NewClient(&mpcCfg, isSign)
+	if err != nil {
+		return err
+	}
+	if isSign && !mpcCfg.IsDKG {
+		return mpcrpc.CheckPublicKey(mpcClient.SignType(), mpcPublicKey)
+	}
+	return nil
+}
+
+// checkECDSAKeyType eth-like transactions can only be signed by ECDSA key
+func checkECDSAKeyType() error {
+	if mpcClient.SignType() != mpcrpc.KeyTypeECDSA {
+		return fmt.Errorf("key type %v can not sign eth-like transaction", mpcClient.SignType())
+	}
 	return nil
 }
 
@@ -95,6 +111,30 @@ func mergeConfigFromConfigFile(ctx *cli.Context) {
 	if config.MPC.RPCTimeout != 0 && !ctx.IsSet(rpcTimeoutFlag.Name) {
 		mpcCfg.RPCTimeout = config.MPC.RPCTimeout
 	}
+	if len(config.MPC.RPCAddresses) > 0 && !ctx.IsSet(mpcServerFlag.Name) {
+		mpcCfg.RPCAddresses = config.MPC.RPCAddresses
+	}
+	if config.MPC.RPCStrategy != "" {
+		mpcCfg.RPCStrategy = config.MPC.RPCStrategy
+	}
+	if config.MPC.RPCUnhealthyDuration != 0 {
+		mpcCfg.RPCUnhealthyDuration = config.MPC.RPCUnhealthyDuration
+	}
+	if config.MPC.RPCRetryTimes != nil {
+		mpcCfg.RPCRetryTimes = config.MPC.RPCRetryTimes
+	}
+	if config.MPC.RPCRetryBackoff != 0 {
+		mpcCfg.RPCRetryBackoff = config.MPC.RPCRetryBackoff
+	}
+	if config.MPC.SignPollInterval != 0 {
+		mpcCfg.SignPollInterval = config.MPC.SignPollInterval
+	}
+	if config.MPC.DKGPollInterval != 0 {
+		mpcCfg.DKGPollInterval = config.MPC.DKGPollInterval
+	}
+	if config.MPC.PollMaxInterval != 0 {
+		mpcCfg.PollMaxInterval = config.MPC.PollMaxInterval
+	}
 	if config.MPC.KeystoreFile != "" && !ctx.IsSet(mpcKeystoreFlag.Name) {
 		mpcCfg.KeystoreFile = config.MPC.KeystoreFile
 	}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+)
+
+// acceptJournal records accept sign decisions if journal file is specified
+var acceptJournal *journal
+
+// journalAlert is the decision of alert entries, which are not sign decisions
+const journalAlert = "ALERT"
+
+// journalEntry an accept sign decision and the rpc result of it
+type journalEntry struct {
+	Time       int64             `json:"time"`
+	KeyID      string            `json:"keyID"`
+	MsgHash    []string          `json:"msgHash"`
+	MsgContext []string          `json:"msgContext"`
+	Decoded    map[string]string `json:"decoded,omitempty"`
+	Decision   string            `json:"decision"`
+	Reason     string            `json:"reason,omitempty"`
+	RPCResult  string            `json:"rpcResult,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// journal is an append only file of json lines,
+// the entries are loaded in memory to query decisions.
+type journal struct {
+	mu      sync.Mutex
+	file    *os.File
+	partial bool // the last line is partially written without newline
+	entries []*journalEntry
+	decided map[string]*journalEntry // the last successful entry of keyID
+}
+
+func initAcceptJournal(ctx *cli.Context) (err error) {
+	journalFile := ctx.String(journalFlag.Name)
+	if journalFile == "" {
+		return nil
+	}
+	acceptJournal, err = openJournal(journalFile, true)
+	if err != nil {
+		return err
+	}
+	log.Info("open accept journal success", "file", journalFile, "entries", len(acceptJournal.entries))
+	return nil
+}
+
+func openJournal(journalFile string, writable bool) (*journal, error) {
+	j := &journal{decided: make(map[string]*journalEntry)}
+	f, err := os.Open(journalFile)
+	switch {
+	case err == nil:
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(strings.TrimSpace(scanner.Text())) == 0 {
+				continue
+			}
+			entry := &journalEntry{}
+			if err = json.Unmarshal(scanner.Bytes(), entry); err != nil {
+				// the last line may be partially written
+				log.Warn("skip wrong journal entry", "file", journalFile, "line", line, "err", err)
+				continue
+			}
+			j.addEntry(entry)
+		}
+		if err = scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read journal failed, %w", err)
+		}
+		if j.partial, err = endsWithoutNewline(f); err != nil {
+			return nil, fmt.Errorf("read journal failed, %w", err)
+		}
+	case !os.IsNotExist(err) || !writable:
+		return nil, fmt.Errorf("open journal failed, %w", err)
+	}
+	if writable {
+		j.file, err = os.OpenFile(journalFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("open journal failed, %w", err)
+		}
+	}
+	return j, nil
+}
+
+func endsWithoutNewline(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err = f.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// close closes the journal file, it's safe to call on nil journal
+func (j *journal) close() {
+	if j == nil || j.file == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.file.Close(); err != nil {
+		log.Warn("close journal failed", "err", err)
+	}
+	j.file = nil
+}
+
+func (j *journal) addEntry(entry *journalEntry) {
+	j.entries = append(j.entries, entry)
+	if entry.Error == "" && entry.Decision != journalAlert {
+		j.decided[strings.ToLower(entry.KeyID)] = entry
+	}
+}
+
+// getDecision returns the last successful accept entry of keyID
+func (j *journal) getDecision(keyID string) *journalEntry {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.decided[strings.ToLower(keyID)]
+}
+
+func (j *journal) record(entry *journalEntry) error {
+	if j == nil {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+	if j.partial {
+		// do not append to the partially written last line
+		data = append([]byte{'\n'}, data...)
+	}
+	if _, err = j.file.Write(data); err != nil {
+		return fmt.Errorf("write journal failed, %w", err)
+	}
+	j.partial = false
+	if err = j.file.Sync(); err != nil {
+		return fmt.Errorf("sync journal failed, %w", err)
+	}
+	j.addEntry(entry)
+	return nil
+}
+
+func recordAcceptSign(keyID, agreeResult, reason string, msgHashes, msgContexts []string, result string, rpcErr error) {
+	entry := &journalEntry{
+		Time:       time.Now().Unix(),
+		KeyID:      keyID,
+		MsgHash:    msgHashes,
+		MsgContext: msgContexts,
+		Decoded:    decodeSignContext(&mpcrpc.SignInfoData{Key: keyID, MsgHash: msgHashes, MsgContext: msgContexts}),
+		Decision:   agreeResult,
+		Reason:     reason,
+		RPCResult:  result,
+	}
+	if rpcErr != nil {
+		entry.Error = rpcErr.Error()
+	}
+	if err := acceptJournal.record(entry); err != nil {
+		log.Error("record accept sign to journal failed", "keyID", keyID, "err", err)
+	}
+}
+
+// recordLimitAlert records the sign refused by limits as an alert entry
+func recordLimitAlert(keyID, reason string, msgHashes, msgContexts []string) {
+	entry := &journalEntry{
+		Time:       time.Now().Unix(),
+		KeyID:      keyID,
+		MsgHash:    msgHashes,
+		MsgContext: msgContexts,
+		Decoded:    decodeSignContext(&mpcrpc.SignInfoData{Key: keyID, MsgHash: msgHashes, MsgContext: msgContexts}),
+		Decision:   journalAlert,
+		Reason:     reason,
+	}
+	if err := acceptJournal.record(entry); err != nil {
+		log.Error("record limit alert to journal failed", "keyID", keyID, "err", err)
+	}
+}
+
+// decodeSignContext summarizes the message context for journal
+func decodeSignContext(info *mpcrpc.SignInfoData) map[string]string {
+	sc, err := parseSignContext(info)
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	decoded := map[string]string{"type": sc.contextType}
+	if sc.tx == nil {
+		return decoded
+	}
+	tx := sc.tx
+	decoded["chainID"] = sc.chainID.String()
+	decoded["nonce"] = fmt.Sprint(tx.Nonce())
+	decoded["value"] = tx.Value().String()
+	if tx.To() != nil {
+		decoded["to"] = tx.To().String()
+	} else {
+		decoded["to"] = "create contract"
+	}
+	if data := tx.Data(); len(data) >= 4 {
+		decoded["method"] = hexutil.Encode(data[:4])
+	}
+	if sc.initiator != nil {
+		decoded["initiator"] = sc.initiator.String()
+	}
+	return decoded
+}
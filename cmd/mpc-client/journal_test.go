@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	journalFile := filepath.Join(t.TempDir(), "journal.jsonl")
+	var err error
+	acceptJournal, err = openJournal(journalFile, true)
+	assert.Nil(t, err)
+	defer func() { acceptJournal = nil }()
+
+	keyID1 := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	keyID2 := "0x0000000000000000000000000000000000000000000000000000000000000002"
+	recordAcceptSign(keyID1, "AGREE", "", []string{"0x01"}, []string{"plaintext", "hello"}, "success", nil)
+	recordAcceptSign(keyID2, "AGREE", "", []string{"0x02"}, []string{"plaintext", "hello"}, "", errors.New("timeout"))
+
+	j, err := openJournal(journalFile, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(j.entries))
+	assert.Equal(t, "plaintext", j.entries[0].Decoded["type"])
+	assert.Equal(t, "success", j.getDecision(keyID1).RPCResult)
+	// failed rpc is not decided
+	assert.Nil(t, j.getDecision(keyID2))
+
+	// alert is not a decision
+	recordLimitAlert(keyID2, "limit exceeded", []string{"0x02"}, []string{"plaintext", "hello"})
+	assert.Nil(t, acceptJournal.getDecision(keyID2))
+	assert.Equal(t, journalAlert, acceptJournal.entries[2].Decision)
+	acceptJournal.close()
+
+	// the partially written last line is skipped and not appended to
+	f, err := os.OpenFile(journalFile, os.O_APPEND|os.O_WRONLY, 0600)
+	assert.Nil(t, err)
+	_, err = f.WriteString(`{"keyID":"0x03`)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	acceptJournal, err = openJournal(journalFile, true)
+	assert.Nil(t, err)
+	assert.True(t, acceptJournal.partial)
+	recordAcceptSign(keyID2, "AGREE", "", []string{"0x02"}, []string{"plaintext", "hello"}, "success", nil)
+	acceptJournal.close()
+
+	j, err = openJournal(journalFile, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(j.entries))
+	assert.Equal(t, "success", j.getDecision(keyID2).RPCResult)
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	errLimitExceeded = errors.New("sign policy limit exceeded")
+
+	// transfer(address,uint256)
+	erc20TransferSelector = common.FromHex("0xa9059cbb")
+)
+
+// policyLimit limits the total amount of token spent on chain in the rolling
+// period, the amounts to all receivers are summed if receiver is not specified.
+// the limit of bitcoin-family network is specified by network instead of chainID.
+type policyLimit struct {
+	Name      string
+	ChainID   string
+	Network   string // eg. btc, ltc, doge
+	Token     string // token contract address, empty for native coin
+	Receiver  string
+	Period    string // eg. 1h, 24h
+	MaxAmount string
+
+	chainID   *big.Int
+	period    time.Duration
+	maxAmount *big.Int
+}
+
+// spendRecord an agreed sign and the spendings of it
+type spendRecord struct {
+	KeyID  string   `json:"keyID"`
+	Time   int64    `json:"time"`
+	Spends []*spend `json:"spends,omitempty"`
+}
+
+// spend the receiver of tx fee and contract creation value is empty,
+// they are only counted by limits without receiver.
+type spend struct {
+	ChainID  *big.Int `json:"chainID,omitempty"`
+	Network  string   `json:"network,omitempty"`
+	Token    string   `json:"token,omitempty"`
+	Receiver string   `json:"receiver"`
+	Amount   *big.Int `json:"amount"`
+}
+
+// limitStore persists the agreed signs in file to keep limits across restarts
+type limitStore struct {
+	file    string
+	Records []*spendRecord `json:"records"`
+}
+
+func (l *policyLimit) init() (err error) {
+	if err = l.initChain(); err != nil {
+		return err
+	}
+	l.period, err = time.ParseDuration(l.Period)
+	if err != nil || l.period <= 0 {
+		return fmt.Errorf("wrong period '%v'", l.Period)
+	}
+	if l.MaxAmount == "" {
+		return errors.New("max amount is not specified")
+	}
+	l.maxAmount, err = parseBigIntArgument("max amount", l.MaxAmount)
+	return err
+}
+
+func (l *policyLimit) initChain() error {
+	if l.Network != "" {
+		if _, exist := btcNetworks[l.Network]; !exist {
+			return fmt.Errorf("%w '%v'", errUnknownBtcNetwork, l.Network)
+		}
+		if l.ChainID != "" || l.Token != "" {
+			return errors.New("chainID and token are not allowed with network")
+		}
+		return nil
+	}
+	var ok bool
+	if l.chainID, ok = new(big.Int).SetString(l.ChainID, 0); !ok {
+		return fmt.Errorf("wrong chainID '%v'", l.ChainID)
+	}
+	for _, addr := range []string{l.Token, l.Receiver} {
+		if addr != "" && !common.IsHexAddress(addr) {
+			return fmt.Errorf("wrong address '%v'", addr)
+		}
+	}
+	return nil
+}
+
+func (l *policyLimit) match(s *spend) bool {
+	if l.Network != "" {
+		return l.Network == s.Network &&
+			(l.Receiver == "" || l.Receiver == s.Receiver)
+	}
+	return s.ChainID != nil && l.chainID.Cmp(s.ChainID) == 0 &&
+		strings.EqualFold(l.Token, s.Token) &&
+		(l.Receiver == "" || strings.EqualFold(l.Receiver, s.Receiver))
+}
+
+func (p *signPolicy) hasLimits() bool {
+	return len(p.Limits) > 0 || p.MaxAgreesPerHour > 0
+}
+
+// checkLimits returns the record of sign if it agrees within limits,
+// the sign already recorded (eg. accept failed last time) is not counted again.
+func (p *signPolicy) checkLimits(store *limitStore, keyID string, sc *signContext, now time.Time) (*spendRecord, error) {
+	for _, rec := range store.Records {
+		if rec.KeyID == keyID {
+			return rec, nil
+		}
+	}
+	spends, err := p.spendsOfContext(sc)
+	if err != nil {
+		return nil, err
+	}
+	if p.MaxAgreesPerHour > 0 {
+		agrees := store.countSince(now.Add(-time.Hour).Unix())
+		if agrees >= p.MaxAgreesPerHour {
+			return nil, fmt.Errorf("%w, agreed %v signs in the last hour", errLimitExceeded, agrees)
+		}
+	}
+	for _, limit := range p.Limits {
+		amount := big.NewInt(0)
+		for _, s := range spends {
+			if limit.match(s) {
+				amount.Add(amount, s.Amount)
+			}
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+		spent := store.spentSince(limit, now.Add(-limit.period).Unix())
+		if total := new(big.Int).Add(spent, amount); total.Cmp(limit.maxAmount) > 0 {
+			return nil, fmt.Errorf("%w, limit '%v' spent %v, amount %v, max %v in %v",
+				errLimitExceeded, limit.Name, spent, amount, limit.maxAmount, limit.period)
+		}
+	}
+	return &spendRecord{KeyID: keyID, Time: now.Unix(), Spends: spends}, nil
+}
+
+// spendsOfContext returns the max fee, native value and erc20 transfer amount of tx,
+// the spends of batch are the sum of its entries.
+// other calls on chain with token limits are refused as spendings are unknown
+// (eg. router or bridge calls moving tokens), and so are the non-tx contexts if there are limits.
+func (p *signPolicy) spendsOfContext(sc *signContext) (spends []*spend, err error) {
+	if sc == nil {
+		return nil, nil
+	}
+	if sc.contextType == "batch" {
+		for i, entry := range sc.entries {
+			entrySpends, errs := p.spendsOfContext(entry)
+			if errs != nil {
+				return nil, fmt.Errorf("batch entry %v: %w", i+1, errs)
+			}
+			spends = append(spends, entrySpends...)
+		}
+		return spends, nil
+	}
+	if sc.btc != nil {
+		return sc.btc.spends(), nil
+	}
+	if sc.tx == nil {
+		if len(p.Limits) > 0 {
+			return nil, fmt.Errorf("%w, unknown spending of %v context", errLimitExceeded, sc.contextType)
+		}
+		return nil, nil
+	}
+	tx := sc.tx
+	if fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap()); fee.Sign() > 0 {
+		spends = append(spends, &spend{ChainID: sc.chainID, Amount: fee})
+	}
+	if tx.To() == nil {
+		if tx.Value().Sign() > 0 {
+			spends = append(spends, &spend{ChainID: sc.chainID, Amount: tx.Value()})
+		}
+		if limit := p.tokenLimitOnChain(sc.chainID); limit != nil {
+			return nil, fmt.Errorf("%w, unknown spending of creating contract with token limit '%v'", errLimitExceeded, limit.Name)
+		}
+		return spends, nil
+	}
+	to := tx.To().String()
+	if tx.Value().Sign() > 0 {
+		spends = append(spends, &spend{ChainID: sc.chainID, Receiver: to, Amount: tx.Value()})
+	}
+	data := tx.Data()
+	if len(data) == 0 {
+		return spends, nil
+	}
+	if len(data) == 4+2*common.HashLength && bytes.Equal(data[:4], erc20TransferSelector) {
+		spends = append(spends, &spend{
+			ChainID:  sc.chainID,
+			Token:    to,
+			Receiver: common.BytesToAddress(data[4:36]).String(),
+			Amount:   new(big.Int).SetBytes(data[36:68]),
+		})
+		return spends, nil
+	}
+	if limit := p.tokenLimitOnChain(sc.chainID); limit != nil {
+		return nil, fmt.Errorf("%w, unknown spending of calling %v method %x with token limit '%v'", errLimitExceeded, to, data[:minInt(len(data), 4)], limit.Name)
+	}
+	return spends, nil
+}
+
+// tokenLimitOnChain returns the first token limit on chain
+func (p *signPolicy) tokenLimitOnChain(chainID *big.Int) *policyLimit {
+	for _, limit := range p.Limits {
+		if limit.Token != "" && limit.chainID.Cmp(chainID) == 0 {
+			return limit
+		}
+	}
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// spends returns the fee and the values of outputs excluding change
+func (b *btcSignContext) spends() (spends []*spend) {
+	var totalIn, totalOut int64
+	for _, amount := range b.amounts {
+		totalIn += amount
+	}
+	for _, txOut := range b.tx.TxOut {
+		totalOut += txOut.Value
+		if bytes.Equal(txOut.PkScript, b.signer.pkScript) {
+			continue
+		}
+		receiver := hex.EncodeToString(txOut.PkScript)
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, b.signer.params)
+		if err == nil && len(addrs) == 1 {
+			receiver = addrs[0].EncodeAddress()
+		}
+		spends = append(spends, &spend{Network: b.network, Receiver: receiver, Amount: big.NewInt(txOut.Value)})
+	}
+	if fee := totalIn - totalOut; fee > 0 {
+		spends = append(spends, &spend{Network: b.network, Amount: big.NewInt(fee)})
+	}
+	return spends
+}
+
+func loadLimitStore(file string) (*limitStore, error) {
+	store := &limitStore{file: file}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read limit store failed, %w", err)
+	}
+	if err = json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("wrong limit store %v, %w", file, err)
+	}
+	return store, nil
+}
+
+func (s *limitStore) countSince(since int64) (count uint64) {
+	for _, rec := range s.Records {
+		if rec.Time > since {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *limitStore) spentSince(limit *policyLimit, since int64) *big.Int {
+	spent := big.NewInt(0)
+	for _, rec := range s.Records {
+		if rec.Time <= since {
+			continue
+		}
+		for _, sp := range rec.Spends {
+			if limit.match(sp) {
+				spent.Add(spent, sp.Amount)
+			}
+		}
+	}
+	return spent
+}
+
+// add saves the record before accepting, records out of all periods are pruned.
+// the record already in store is moved to the end.
+func (s *limitStore) add(rec *spendRecord, keepSince int64) error {
+	records := make([]*spendRecord, 0, len(s.Records)+1)
+	for _, r := range s.Records {
+		if r.Time > keepSince && r != rec {
+			records = append(records, r)
+		}
+	}
+	s.Records = append(records, rec)
+	return s.save()
+}
+
+// save writes to temp file and renames it to keep the store file intact
+func (s *limitStore) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := s.file + ".tmp"
+	if err = ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("write limit store failed, %w", err)
+	}
+	if err = os.Rename(tmpFile, s.file); err != nil {
+		return fmt.Errorf("write limit store failed, %w", err)
+	}
+	log.Debug("save limit store success", "file", s.file, "records", len(s.Records))
+	return nil
+}
+
+// maxPeriod is the longest period the records must be kept
+func (p *signPolicy) maxPeriod() time.Duration {
+	period := time.Hour
+	for _, limit := range p.Limits {
+		if limit.period > period {
+			period = limit.period
+		}
+	}
+	return period
+}
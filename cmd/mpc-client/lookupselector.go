@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	lookupSelectorCommand = &cli.Command{
+		Action:    lookupSelector,
+		Name:      "lookupselector",
+		Usage:     "look up method selector or event topic in signature registry",
+		ArgsUsage: "",
+		Description: `
+look up the signatures of 4 bytes method selector or 32 bytes event topic,
+all the candidates are shown if different signatures collide.
+if signature is specified, its selector and topic are looked up.`,
+		Flags: []cli.Flag{
+			lookupFlag,
+			abiFilesFlag,
+			signatureDirFlag,
+		},
+	}
+)
+
+func lookupSelector(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	items := ctx.StringSlice(lookupFlag.Name)
+	if len(items) == 0 {
+		return errors.New("must specify selector, topic or signature to look up")
+	}
+	err = loadSignatureRegistry(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, "(") {
+			sig := strings.ReplaceAll(item, " ", "")
+			hash := crypto.Keccak256([]byte(sig))
+			fmt.Printf("signature %v\n", sig)
+			printRegistryEntries("method", hexutil.Encode(hash[:4]), sigRegistry.lookupMethod(hash[:4]))
+			printRegistryEntries("event", hexutil.Encode(hash), sigRegistry.lookupEvent(common.BytesToHash(hash)))
+			continue
+		}
+		data, errf := hexutil.Decode(item)
+		switch {
+		case errf != nil:
+			return fmt.Errorf("wrong selector or topic '%v', %w", item, errf)
+		case len(data) == 4:
+			printRegistryEntries("method", hexutil.Encode(data), sigRegistry.lookupMethod(data))
+		case len(data) == common.HashLength:
+			printRegistryEntries("event", hexutil.Encode(data), sigRegistry.lookupEvent(common.BytesToHash(data)))
+		default:
+			return fmt.Errorf("wrong selector or topic '%v', must be 4 or 32 bytes", item)
+		}
+	}
+	return nil
+}
+
+func printRegistryEntries(kind, key string, entries []*registryEntry) {
+	switch len(entries) {
+	case 0:
+		fmt.Printf("%v %v is unknown\n", kind, key)
+		return
+	case 1:
+		fmt.Printf("%v %v:\n", kind, key)
+	default:
+		fmt.Printf("%v %v has %v candidates (collision):\n", kind, key, len(entries))
+	}
+	for _, entry := range entries {
+		fmt.Printf("  %v (%v)\n", entry.Signature, entry.Source)
+	}
+}
@@ -2,8 +2,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/anyswap/mpc-client/cmd/utils"
 	"github.com/anyswap/mpc-client/log"
@@ -17,6 +19,9 @@ var (
 	gitDate   = ""
 	// The app that holds all commands and flags.
 	app = utils.NewApp(clientIdentifier, gitCommit, gitDate, "the MPC-Client command line interface")
+
+	// bgCtx is canceled when receiving interrupt signal
+	bgCtx = context.Background()
 )
 
 func initApp() {
@@ -27,13 +32,26 @@ func initApp() {
 	app.Commands = []*cli.Command{
 		doDKGCommand,
 		signPlainTextCommand,
+		signTypedDataCommand,
+		personalSignCommand,
+		signBatchCommand,
 		sendEthTxCommand,
+		speedUpTxCommand,
+		cancelTxCommand,
+		buildTxCommand,
+		signTxCommand,
+		broadcastTxCommand,
+		sendBtcTxCommand,
 		acceptSignCommand,
 		withdrawFeeCommand,
 		acceptWithdrawFeeCommand,
+		autoAcceptCommand,
 		getAcceptListCommand,
 		getSignStatusCommand,
+		historyCommand,
 		getEnodeCommand,
+		pubkeyInfoCommand,
+		lookupSelectorCommand,
 		getGroupCommand,
 		utils.LicenseCommand,
 		utils.VersionCommand,
@@ -51,6 +69,9 @@ func initApp() {
 
 func main() {
 	initApp()
+	var cancel context.CancelFunc
+	bgCtx, cancel = utils.CleanupContext()
+	defer cancel()
 	if err := app.Run(os.Args); err != nil {
 		log.Println(err)
 		os.Exit(1)
@@ -65,3 +86,15 @@ func mpcclient(ctx *cli.Context) error {
 
 	return cli.ShowAppHelp(ctx)
 }
+
+// sleepOrCanceled sleeps for duration d, returns false if bgCtx is canceled
+func sleepOrCanceled(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-bgCtx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	buildTxCommand = &cli.Command{
+		Action:    buildTx,
+		Name:      "buildtx",
+		Usage:     "build unsigned eth-like transaction and write it to file",
+		ArgsUsage: "",
+		Description: `
+if no gateways are specified (offline mode), nonce, gas limit and fees must be specified.`,
+		Flags: []cli.Flag{
+			gatewaysFlag,
+			chainIDFlag,
+			createContractFlag,
+			fromAddrFlag,
+			toAddrFlag,
+			nonceFlag,
+			valueFlag,
+			gasLimitFlag,
+			gasPriceFlag,
+			maxFeePerGasFlag,
+			maxPriorityFeePerGasFlag,
+			inputFlag,
+			accessListFlag,
+			gasLimitMultiplierFlag,
+			gasPriceMultiplierFlag,
+			maxGasFeeFlag,
+			maxTotalFeeFlag,
+			outputFlag,
+		},
+	}
+
+	signTxCommand = &cli.Command{
+		Action:      signTx,
+		Name:        "signtx",
+		Usage:       "mpc sign unsigned tx file built by buildtx and write the signed raw tx",
+		ArgsUsage:   "",
+		Description: ``,
+		Flags: []cli.Flag{
+			pubkeyFlag,
+			gidFlag,
+			thresholdFlag,
+			signModeFlag,
+			signMemoFlag,
+			mpcServerFlag,
+			mpcKeystoreFlag,
+			mpcPasswordFlag,
+			signTypeFlag,
+			apiPrefixFlag,
+			rpcTimeoutFlag,
+			signTimeoutFlag,
+			txFileFlag,
+			outputFlag,
+		},
+	}
+
+	broadcastTxCommand = &cli.Command{
+		Action:      broadcastTx,
+		Name:        "broadcasttx",
+		Usage:       "broadcast signed raw tx to gateways",
+		ArgsUsage:   "",
+		Description: ``,
+		Flags: []cli.Flag{
+			gatewaysFlag,
+			rawTxFlag,
+			txFileFlag,
+			waitFlag,
+			confirmationsFlag,
+			waitTimeoutFlag,
+		},
+	}
+)
+
+var (
+	errMissingOfflineTxArguments = errors.New("offline mode (no gateways) requires nonce, gas limit and fees")
+	errMissingRawTx              = errors.New("must specify either rawTx or txFile")
+)
+
+// unsignedTxFile the file format of unsigned tx,
+// chainID is kept as legacy tx does not contain it before signing.
+type unsignedTxFile struct {
+	ChainID *big.Int           `json:"chainID"`
+	From    common.Address     `json:"from"`
+	Tx      *types.Transaction `json:"tx"`
+}
+
+func buildTx(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	err = checkSendEthTxArguments(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(txArgs.gateways) == 0 {
+		err = checkOfflineTxArguments()
+		if err != nil {
+			return err
+		}
+	} else {
+		err = dailGateways(txArgs.gateways)
+		if err != nil {
+			return err
+		}
+	}
+
+	var nonce uint64
+	if txArgs.accNonce != nil {
+		nonce = txArgs.accNonce.Uint64()
+	} else {
+		nonce, err = getPendingNonce(txArgs.from)
+		if err != nil {
+			log.Error("get account nonce failed", "account", txArgs.from.String(), "err", err)
+			return err
+		}
+		log.Info("get account nonce success", "account", txArgs.from.String(), "nonce", nonce)
+	}
+
+	err = fillGasArguments()
+	if err != nil {
+		return err
+	}
+
+	rawTx := buildRawTx(nonce)
+	log.Info("create raw tx success", "type", rawTx.Type())
+
+	data, err := json.MarshalIndent(&unsignedTxFile{
+		ChainID: txArgs.chainID,
+		From:    txArgs.from,
+		Tx:      rawTx,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(ctx.String(outputFlag.Name), data)
+}
+
+// checkOfflineTxArguments checks the arguments can not be filled
+// from gateways are all specified.
+func checkOfflineTxArguments() error {
+	if txArgs.accNonce == nil || txArgs.gasLimit == 0 {
+		return errMissingOfflineTxArguments
+	}
+	if txArgs.gasPrice == nil && (txArgs.maxFeePerGas == nil || txArgs.maxPriorityFeePerGas == nil) {
+		return errMissingOfflineTxArguments
+	}
+	return nil
+}
+
+func signTx(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	mpcCfg.NeedKeyStore = true
+	err = checkAndInitMpcConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+	err = checkECDSAKeyType()
+	if err != nil {
+		return err
+	}
+
+	txFile := ctx.String(txFileFlag.Name)
+	if txFile == "" {
+		return errors.New("must specify tx file")
+	}
+	rawTx, err := loadUnsignedTxFile(txFile)
+	if err != nil {
+		return err
+	}
+	log.Info("load unsigned tx success", "type", rawTx.Type(), "chainID", txArgs.chainID, "from", txArgs.from.String())
+	_ = printTx(rawTx, true)
+
+	signedTx, err := mpcSignTx(rawTx, "ethtx")
+	if err != nil {
+		return err
+	}
+	data, err := signedTx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeOutput(ctx.String(outputFlag.Name), []byte(hexutil.Encode(data)))
+}
+
+// loadUnsignedTxFile load unsigned tx, and set chainID and from of txArgs
+func loadUnsignedTxFile(txFile string) (*types.Transaction, error) {
+	data, err := ioutil.ReadFile(txFile)
+	if err != nil {
+		return nil, fmt.Errorf("read tx file failed, %w", err)
+	}
+	var txData unsignedTxFile
+	err = json.Unmarshal(data, &txData)
+	if err != nil {
+		return nil, fmt.Errorf("wrong tx file %v, %w", txFile, err)
+	}
+	if txData.Tx == nil || txData.ChainID == nil || txData.ChainID.Sign() <= 0 {
+		return nil, fmt.Errorf("wrong tx file %v, missing tx or chainID", txFile)
+	}
+	if v, r, s := txData.Tx.RawSignatureValues(); v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0 {
+		return nil, fmt.Errorf("wrong tx file %v, tx is already signed", txFile)
+	}
+	if txData.Tx.Type() != types.LegacyTxType && txData.Tx.ChainId().Cmp(txData.ChainID) != 0 {
+		return nil, fmt.Errorf("wrong tx file %v, tx chainID %v mismatch with %v", txFile, txData.Tx.ChainId(), txData.ChainID)
+	}
+	txArgs.chainID = txData.ChainID
+	txArgs.from = txData.From
+	return txData.Tx, nil
+}
+
+func broadcastTx(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	checkWaitReceiptArguments(ctx)
+
+	rawTxStr := ctx.String(rawTxFlag.Name)
+	if txFile := ctx.String(txFileFlag.Name); txFile != "" {
+		if rawTxStr != "" {
+			return errors.New("can not specify both rawTx and txFile")
+		}
+		data, errf := ioutil.ReadFile(txFile)
+		if errf != nil {
+			return fmt.Errorf("read tx file failed, %w", errf)
+		}
+		rawTxStr = strings.TrimSpace(string(data))
+	}
+	if rawTxStr == "" {
+		return errMissingRawTx
+	}
+
+	rawTx, err := hexutil.Decode(rawTxStr)
+	if err != nil {
+		return fmt.Errorf("wrong raw tx, %w", err)
+	}
+	signedTx := new(types.Transaction)
+	err = signedTx.UnmarshalBinary(rawTx)
+	if err != nil {
+		return fmt.Errorf("wrong raw tx, %w", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(signedTx.ChainId()), signedTx)
+	if err != nil {
+		return fmt.Errorf("get sender from signed tx failed, %w", err)
+	}
+	log.Info("decode signed tx success", "txHash", signedTx.Hash().String(), "sender", sender.String())
+	_ = printTx(signedTx, false)
+
+	gateways := ctx.StringSlice(gatewaysFlag.Name)
+	if len(gateways) == 0 {
+		return errors.New("must specify gateways")
+	}
+	err = dailGateways(gateways)
+	if err != nil {
+		return err
+	}
+	return sendAndWaitTx(signedTx, sender)
+}
+
+// writeOutput write data to output file, or to stdout if output is empty
+func writeOutput(output string, data []byte) error {
+	if output == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	err := ioutil.WriteFile(output, append(data, '\n'), 0600)
+	if err != nil {
+		return fmt.Errorf("write output file failed, %w", err)
+	}
+	log.Info("write output file success", "file", output)
+	return nil
+}
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"encoding/hex"
-
 	"github.com/anyswap/mpc-client/log"
 	"github.com/ethereum/go-ethereum/core/types"
 )
@@ -275,8 +273,7 @@ func parseEthTx(tx *types.Transaction) {
 	txData := tx.Data()
 	if len(txData) < 4 {
 		log.Println("the tx is not calling contract.")
-	} else if method, exist := knownContractMethods[hex.EncodeToString(txData[:4])]; exist {
-		log.Printf("the tx is calling method => %v", method)
+		return
 	}
-
+	printCallData(txData, decimalsOfToken(to), "", 0)
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	personalSignCommand = &cli.Command{
+		Action:    personalSign,
+		Name:      "personalsign",
+		Usage:     "sign message in EIP-191 personal_sign format (eth_sign compatible)",
+		ArgsUsage: "",
+		Description: `
+the message is specified by 'msgcontext' (plain text) or 'signmsg' (hex string),
+and is signed with the prefix "\x19Ethereum Signed Message:\n" and its length.`,
+		Flags: []cli.Flag{
+			pubkeyFlag,
+			msgHashFlag,
+			msgContextFlag,
+			signMessageFlag,
+			gidFlag,
+			thresholdFlag,
+			signModeFlag,
+			signMemoFlag,
+			mpcServerFlag,
+			mpcKeystoreFlag,
+			mpcPasswordFlag,
+			signTypeFlag,
+			apiPrefixFlag,
+			rpcTimeoutFlag,
+			signTimeoutFlag,
+		},
+	}
+)
+
+func personalSign(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	mpcCfg.NeedKeyStore = true
+	err = checkAndInitMpcConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+	err = checkECDSAKeyType()
+	if err != nil {
+		return err
+	}
+
+	var message []byte
+	switch {
+	case signMessageArg != "" && msgContextArg != "":
+		return errors.New("can not specify both msgcontext and signmsg")
+	case signMessageArg != "":
+		msg := signMessageArg
+		if !has0xPrefix(msg) {
+			msg = "0x" + msg
+		}
+		message, err = hexutil.Decode(msg)
+		if err != nil {
+			return fmt.Errorf("wrong sign message '%v', %w", signMessageArg, err)
+		}
+	case msgContextArg != "":
+		message = []byte(msgContextArg)
+	default:
+		return errors.New("must specify either msgcontext or signmsg")
+	}
+
+	msgHash := common.BytesToHash(accounts.TextHash(message))
+	if msgHashArg != "" {
+		hash, errf := hexutil.Decode(msgHashArg)
+		if errf != nil || len(hash) != common.HashLength {
+			return fmt.Errorf("wrong message hash '%v', must be 32 bytes hex string", msgHashArg)
+		}
+		if common.BytesToHash(hash) != msgHash {
+			return fmt.Errorf("message hash %v mismatch with the personal sign hash %v", msgHashArg, msgHash.String())
+		}
+	}
+	log.Info("calc personal sign hash success", "msgHash", msgHash.String())
+
+	msgContext := []string{"personalsign", hexutil.Encode(message)}
+	if signMemoArg != "" {
+		msgContext = append(msgContext, signMemoArg)
+	}
+
+	keyID, rsvs, err := mpcClient.DoSignContext(bgCtx, mpcPublicKey, []string{msgHash.String()}, msgContext)
+	if err != nil {
+		log.Error("mpc sign failed", "err", err)
+		return err
+	}
+	log.Info("mpc sign success", "keyID", keyID)
+
+	if len(rsvs) != 1 {
+		log.Error("mpc sign result rsv count is wrong", "have", len(rsvs), "want", 1)
+		return errors.New("mpc sign result rsv count is wrong")
+	}
+	rsv := rsvs[0]
+
+	signature := common.FromHex(rsv)
+	if len(signature) != crypto.SignatureLength {
+		log.Error("mpc sign result rsv length is wrong", "rsv", rsv)
+		return errors.New("mpc sign result rsv length is wrong")
+	}
+	pubkey, err := crypto.SigToPub(msgHash.Bytes(), signature)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("signer is", crypto.PubkeyToAddress(*pubkey).String())
+	fmt.Println("rsv is", rsv)
+	fmt.Println("signature is", hexutil.Encode(toLegacyVSignature(signature)))
+	return nil
+}
+
+// toLegacyVSignature converts the recovery id v from 0/1 to 27/28 as wallets expect
+func toLegacyVSignature(signature []byte) []byte {
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	return sig
+}
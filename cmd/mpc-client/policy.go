@@ -0,0 +1,631 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// policy outcomes
+const (
+	policyAgree    = "agree"
+	policyDisagree = "disagree"
+	policyIgnore   = "ignore"
+)
+
+var (
+	errWrongPolicy = errors.New("wrong sign policy")
+
+	// context types can be agreed by policy, batch is agreed if all entries are agreed
+	agreeableContextTypes = []string{"ethtx", "replacetx", "withdrawfee", "btctx"}
+)
+
+// signPolicy rules are evaluated in order, the first matched rule decides
+// the outcome, the default outcome is used if no rule matches.
+// the entries of batch are evaluated individually, and the batch is agreed
+// only if all entries are agreed.
+// the agreed signs are refused if exceeding the limits.
+type signPolicy struct {
+	Default          string
+	MaxAgreesPerHour uint64
+	Limits           []*policyLimit
+	Rules            []*policyRule
+}
+
+// policyRule all the specified conditions must be matched,
+// the conditions of tx are only matched by tx contexts,
+// the conditions of btc tx are only matched by btctx contexts.
+// agree rules only match the agreeable context types.
+type policyRule struct {
+	Name         string
+	Outcome      string
+	ContextTypes []string // eg. ethtx, withdrawfee, replacetx, btctx, plaintext
+	ChainIDs     []string
+	To           []string
+	Initiators   []string // signer of the initiator signature of withdrawfee context
+	Methods      []string // 4 bytes selector or method signature
+	MaxValue     string   // max native value of tx
+	MaxFee       string   // max fee of tx (gas limit * gas price or max fee per gas)
+	AllowCreate  bool     // allow contract creation tx
+	Args         []*policyArgRule
+
+	BtcNetworks  []string // eg. btc, ltc, doge
+	BtcReceivers []string // output addresses, change outputs to the signer are excluded
+	MaxBtcValue  string   // max total value of outputs excluding change in satoshis
+	MaxBtcFee    string   // max fee in satoshis
+
+	chainIDs    []*big.Int
+	maxValue    *big.Int
+	maxFee      *big.Int
+	methods     map[string]*policyMethod // key is hex selector
+	maxBtcValue *big.Int
+	maxBtcFee   *big.Int
+}
+
+// policyArgRule constraint of the decoded argument at index,
+// In is the allowed values, Min and Max are the integer range.
+type policyArgRule struct {
+	Index int
+	In    []string
+	Min   string
+	Max   string
+
+	min *big.Int
+	max *big.Int
+}
+
+type policyMethod struct {
+	signature string
+	args      abi.Arguments
+}
+
+// policyDecision decision of a sign info
+type policyDecision struct {
+	Outcome string
+	Rule    string
+	Reason  string
+
+	context *signContext
+}
+
+// signContext parsed message context of sign info,
+// entries are the contexts of batch entries.
+type signContext struct {
+	contextType string
+	chainID     *big.Int
+	tx          *types.Transaction
+	initiator   *common.Address
+	btc         *btcSignContext
+	entries     []*signContext
+}
+
+// btcSignContext parsed bitcoin-family tx and its input amounts
+type btcSignContext struct {
+	network string
+	signer  *btcSigner
+	tx      *wire.MsgTx
+	amounts []int64
+}
+
+func loadSignPolicy(policyFile string) (*signPolicy, error) {
+	data, err := ioutil.ReadFile(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read policy file failed, %w", err)
+	}
+	policy := &signPolicy{}
+	if strings.HasSuffix(strings.ToLower(policyFile), ".json") {
+		err = json.Unmarshal(data, policy)
+	} else {
+		_, err = toml.Decode(string(data), policy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w, decode %v failed, %v", errWrongPolicy, policyFile, err)
+	}
+	if err = policy.init(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (p *signPolicy) init() error {
+	if p.Default == "" {
+		p.Default = policyIgnore
+	}
+	// agree must be decided by rules explicitly
+	if p.Default != policyDisagree && p.Default != policyIgnore {
+		return fmt.Errorf("%w, default outcome must be disagree or ignore", errWrongPolicy)
+	}
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if err := rule.init(); err != nil {
+			return fmt.Errorf("%w, rule '%v': %v", errWrongPolicy, rule.Name, err)
+		}
+	}
+	for i, limit := range p.Limits {
+		if limit.Name == "" {
+			limit.Name = fmt.Sprintf("limit-%d", i+1)
+		}
+		if err := limit.init(); err != nil {
+			return fmt.Errorf("%w, limit '%v': %v", errWrongPolicy, limit.Name, err)
+		}
+	}
+	return nil
+}
+
+func checkPolicyOutcome(outcome string) error {
+	switch outcome {
+	case policyAgree, policyDisagree, policyIgnore:
+		return nil
+	default:
+		return fmt.Errorf("%w, unknown outcome '%v'", errWrongPolicy, outcome)
+	}
+}
+
+func (r *policyRule) init() (err error) {
+	r.Outcome = strings.ToLower(r.Outcome)
+	if err = checkPolicyOutcome(r.Outcome); err != nil {
+		return err
+	}
+	for _, contextType := range r.ContextTypes {
+		if strings.EqualFold(contextType, "batch") {
+			return errors.New("batch context type is not allowed, batch entries are evaluated individually")
+		}
+		if r.Outcome == policyAgree && !containsFold(agreeableContextTypes, contextType) {
+			return fmt.Errorf("context type %v can not be agreed by policy", contextType)
+		}
+	}
+	if err = r.initBtcConditions(); err != nil {
+		return err
+	}
+	for _, chainIDStr := range r.ChainIDs {
+		chainID, ok := new(big.Int).SetString(chainIDStr, 0)
+		if !ok {
+			return fmt.Errorf("wrong chainID '%v'", chainIDStr)
+		}
+		r.chainIDs = append(r.chainIDs, chainID)
+	}
+	for _, addrs := range [][]string{r.To, r.Initiators} {
+		for _, addr := range addrs {
+			if !common.IsHexAddress(addr) {
+				return fmt.Errorf("wrong address '%v'", addr)
+			}
+		}
+	}
+	if r.MaxValue != "" {
+		r.maxValue, err = parseBigIntArgument("max value", r.MaxValue)
+		if err != nil {
+			return err
+		}
+	}
+	if r.MaxFee != "" {
+		r.maxFee, err = parseBigIntArgument("max fee", r.MaxFee)
+		if err != nil {
+			return err
+		}
+	}
+	if len(r.Methods) > 0 {
+		r.methods = make(map[string]*policyMethod, len(r.Methods))
+	}
+	for _, method := range r.Methods {
+		selector, pm, errf := parsePolicyMethod(method, len(r.Args) > 0)
+		if errf != nil {
+			return errf
+		}
+		r.methods[selector] = pm
+	}
+	for _, arg := range r.Args {
+		if len(r.methods) == 0 {
+			return errors.New("argument constraints require method signatures")
+		}
+		for _, pm := range r.methods {
+			if arg.Index < 0 || arg.Index >= len(pm.args) {
+				return fmt.Errorf("argument index %v out of range of method %v", arg.Index, pm.signature)
+			}
+		}
+		var ok bool
+		if arg.Min != "" {
+			if arg.min, ok = new(big.Int).SetString(arg.Min, 0); !ok {
+				return fmt.Errorf("wrong argument min '%v'", arg.Min)
+			}
+		}
+		if arg.Max != "" {
+			if arg.max, ok = new(big.Int).SetString(arg.Max, 0); !ok {
+				return fmt.Errorf("wrong argument max '%v'", arg.Max)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *policyRule) initBtcConditions() (err error) {
+	for _, network := range r.BtcNetworks {
+		if _, exist := btcNetworks[network]; !exist {
+			return fmt.Errorf("%w '%v'", errUnknownBtcNetwork, network)
+		}
+	}
+	if r.MaxBtcValue != "" {
+		if r.maxBtcValue, err = parseBigIntArgument("max btc value", r.MaxBtcValue); err != nil {
+			return err
+		}
+	}
+	if r.MaxBtcFee != "" {
+		if r.maxBtcFee, err = parseBigIntArgument("max btc fee", r.MaxBtcFee); err != nil {
+			return err
+		}
+	}
+	if r.hasBtcConditions() && r.hasTxConditions() {
+		return errors.New("can not mix conditions of tx and btc tx")
+	}
+	return nil
+}
+
+// parsePolicyMethod parse 4 bytes selector or method signature,
+// the argument types are parsed if needArgs.
+func parsePolicyMethod(method string, needArgs bool) (selector string, pm *policyMethod, err error) {
+	method = strings.TrimSpace(method)
+	if !strings.Contains(method, "(") {
+		selector = strings.ToLower(strings.TrimPrefix(method, "0x"))
+		if sel, errf := hex.DecodeString(selector); errf != nil || len(sel) != 4 {
+			return "", nil, fmt.Errorf("wrong method selector '%v'", method)
+		}
+		if needArgs {
+			return "", nil, fmt.Errorf("argument constraints require method signature, not selector '%v'", method)
+		}
+		return selector, &policyMethod{signature: method}, nil
+	}
+	pm = &policyMethod{signature: method}
+	selector = hex.EncodeToString(crypto.Keccak256([]byte(method))[:4])
+	if !needArgs {
+		return selector, pm, nil
+	}
+	abiMethod, err := parseMethodSignature(method)
+	if err != nil {
+		return "", nil, err
+	}
+	pm.args = abiMethod.Inputs
+	return selector, pm, nil
+}
+
+// evaluate decide the outcome of sign info, agree is changed
+// to disagree if the message hash mismatches with the context.
+func (p *signPolicy) evaluate(info *mpcrpc.SignInfoData) *policyDecision {
+	sc, err := parseSignContext(info)
+	if err != nil {
+		return &policyDecision{Outcome: p.Default, Reason: err.Error()}
+	}
+	var decision *policyDecision
+	if sc.contextType == "batch" {
+		decision = p.decideBatch(info.Key, sc)
+	} else {
+		decision = p.decide(info.Key, sc)
+	}
+	decision.context = sc
+	if decision.Outcome == policyAgree {
+		if err = verifySignInfo(info); err != nil {
+			decision.Outcome = policyDisagree
+			decision.Reason = err.Error()
+		}
+	}
+	return decision
+}
+
+// decide returns the outcome of the first matched rule
+func (p *signPolicy) decide(keyID string, sc *signContext) *policyDecision {
+	for _, rule := range p.Rules {
+		if errm := rule.match(sc); errm != nil {
+			log.Debug("sign policy rule not match", "keyID", keyID, "rule", rule.Name, "reason", errm)
+			continue
+		}
+		return &policyDecision{Outcome: rule.Outcome, Rule: rule.Name}
+	}
+	return &policyDecision{Outcome: p.Default, Reason: "no rule matches"}
+}
+
+// decideBatch agrees only if all entries are agreed,
+// otherwise disagrees if any entry is disagreed, or ignores.
+func (p *signPolicy) decideBatch(keyID string, sc *signContext) *policyDecision {
+	if len(sc.entries) == 0 {
+		return &policyDecision{Outcome: p.Default, Reason: "empty batch"}
+	}
+	decision := &policyDecision{Outcome: policyAgree}
+	rules := make([]string, len(sc.entries))
+	for i, entry := range sc.entries {
+		d := p.decide(keyID, entry)
+		rules[i] = d.Rule
+		if d.Rule == "" {
+			rules[i] = "default"
+		}
+		if d.Outcome == policyAgree || decision.Outcome == policyDisagree {
+			continue
+		}
+		decision.Outcome = d.Outcome
+		decision.Reason = fmt.Sprintf("batch entry %v is %v by rule '%v'", i+1, d.Outcome, rules[i])
+		if d.Reason != "" {
+			decision.Reason += ", " + d.Reason
+		}
+	}
+	decision.Rule = "batch[" + strings.Join(rules, ", ") + "]"
+	return decision
+}
+
+func parseSignContext(info *mpcrpc.SignInfoData) (*signContext, error) {
+	msgContexts := info.MsgContext
+	if len(msgContexts) == 0 {
+		return nil, errors.New("empty message context")
+	}
+	sc := &signContext{contextType: strings.ToLower(msgContexts[0])}
+	switch sc.contextType {
+	case "ethtx", "replacetx", "withdrawfee":
+	case "btctx":
+		return parseBtcSignContext(info, sc)
+	case "batch":
+		return parseBatchSignContext(info, sc)
+	default:
+		return sc, nil
+	}
+
+	if len(msgContexts) < 3 {
+		return nil, errors.New("wrong message context length, must have at least three elements")
+	}
+	var ok bool
+	sc.chainID, ok = new(big.Int).SetString(msgContexts[2], 0)
+	if !ok {
+		return nil, fmt.Errorf("wrong chainID '%v'", msgContexts[2])
+	}
+	sc.tx = new(types.Transaction)
+	if err := json.Unmarshal([]byte(msgContexts[1]), sc.tx); err != nil {
+		return nil, fmt.Errorf("json unmarshal msgContext to tx failed. %w", err)
+	}
+	if sc.tx.Type() != types.LegacyTxType && sc.tx.ChainId().Cmp(sc.chainID) != 0 {
+		return nil, fmt.Errorf("tx chainID %v mismatch with context chainID %v", sc.tx.ChainId(), sc.chainID)
+	}
+
+	if sc.contextType == "withdrawfee" && len(msgContexts) > 3 && len(info.MsgHash) == 1 {
+		signature := common.FromHex(msgContexts[3])
+		if pub, err := crypto.SigToPub(common.HexToHash(info.MsgHash[0]).Bytes(), signature); err == nil {
+			initiator := crypto.PubkeyToAddress(*pub)
+			sc.initiator = &initiator
+		}
+	}
+	return sc, nil
+}
+
+func parseBtcSignContext(info *mpcrpc.SignInfoData, sc *signContext) (*signContext, error) {
+	if len(info.MsgContext) < 2 {
+		return nil, errors.New("wrong message context length, must have at least two elements")
+	}
+	var btcContext btcTxContext
+	if err := json.Unmarshal([]byte(info.MsgContext[1]), &btcContext); err != nil {
+		return nil, fmt.Errorf("json unmarshal msgContext to btctx failed. %w", err)
+	}
+	signer, err := newBtcSigner(btcContext.Network, btcContext.AddressType, info.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := decodeBtcTx(btcContext.RawTx)
+	if err != nil {
+		return nil, err
+	}
+	if len(btcContext.Amounts) != len(tx.TxIn) {
+		return nil, fmt.Errorf("input amount count %v mismatch with input count %v", len(btcContext.Amounts), len(tx.TxIn))
+	}
+	sc.btc = &btcSignContext{network: btcContext.Network, signer: signer, tx: tx, amounts: btcContext.Amounts}
+	return sc, nil
+}
+
+// parseBatchSignContext parse the contexts of entries, nested batch is not allowed
+func parseBatchSignContext(info *mpcrpc.SignInfoData, sc *signContext) (*signContext, error) {
+	entries, err := parseBatchEntries(info)
+	if err != nil {
+		return nil, err
+	}
+	sc.entries = make([]*signContext, len(entries))
+	for i, entry := range entries {
+		entrySC, err := parseSignContext(entry)
+		if err != nil {
+			return nil, fmt.Errorf("batch entry %v: %w", i+1, err)
+		}
+		sc.entries[i] = entrySC
+	}
+	return sc, nil
+}
+
+func (r *policyRule) match(sc *signContext) error {
+	if len(r.ContextTypes) > 0 && !containsFold(r.ContextTypes, sc.contextType) {
+		return fmt.Errorf("context type %v not allowed", sc.contextType)
+	}
+	if r.Outcome == policyAgree && !containsFold(agreeableContextTypes, sc.contextType) {
+		return fmt.Errorf("context type %v can not be agreed by policy", sc.contextType)
+	}
+	if r.hasBtcConditions() {
+		return r.matchBtc(sc)
+	}
+	if !r.hasTxConditions() {
+		return nil
+	}
+	tx := sc.tx
+	if tx == nil {
+		return fmt.Errorf("context type %v is not tx", sc.contextType)
+	}
+	if len(r.chainIDs) > 0 && !containsBigInt(r.chainIDs, sc.chainID) {
+		return fmt.Errorf("chainID %v not allowed", sc.chainID)
+	}
+	if len(r.Initiators) > 0 && (sc.initiator == nil || !containsFold(r.Initiators, sc.initiator.String())) {
+		return errors.New("initiator not allowed")
+	}
+	if tx.To() == nil {
+		if !r.AllowCreate {
+			return errors.New("create contract tx not allowed")
+		}
+	} else if len(r.To) > 0 && !containsFold(r.To, tx.To().String()) {
+		return fmt.Errorf("to address %v not allowed", tx.To().String())
+	}
+	if r.maxValue != nil && tx.Value().Cmp(r.maxValue) > 0 {
+		return fmt.Errorf("value %v exceeds max value %v", tx.Value(), r.maxValue)
+	}
+	if fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap()); r.maxFee != nil && fee.Cmp(r.maxFee) > 0 {
+		return fmt.Errorf("fee %v exceeds max fee %v", fee, r.maxFee)
+	}
+	if len(r.methods) == 0 {
+		return nil
+	}
+	data := tx.Data()
+	if len(data) < 4 {
+		return errors.New("tx is not calling contract")
+	}
+	pm, exist := r.methods[hex.EncodeToString(data[:4])]
+	if !exist {
+		return fmt.Errorf("method %x not allowed", data[:4])
+	}
+	if len(r.Args) == 0 {
+		return nil
+	}
+	values, err := pm.args.UnpackValues(data[4:])
+	if err != nil {
+		return fmt.Errorf("decode arguments of %v failed, %w", pm.signature, err)
+	}
+	for _, arg := range r.Args {
+		if err = arg.match(values[arg.Index]); err != nil {
+			return fmt.Errorf("argument %v of %v: %w", arg.Index, pm.signature, err)
+		}
+	}
+	return nil
+}
+
+func (r *policyRule) hasTxConditions() bool {
+	return len(r.chainIDs) > 0 || len(r.To) > 0 || len(r.Initiators) > 0 ||
+		len(r.methods) > 0 || r.maxValue != nil || r.maxFee != nil || r.AllowCreate
+}
+
+func (r *policyRule) hasBtcConditions() bool {
+	return len(r.BtcNetworks) > 0 || len(r.BtcReceivers) > 0 || r.maxBtcValue != nil || r.maxBtcFee != nil
+}
+
+// matchBtc matches the outputs and fee of btc tx, the input amounts
+// are verified by the message hashes before agreeing.
+func (r *policyRule) matchBtc(sc *signContext) error {
+	b := sc.btc
+	if b == nil {
+		return fmt.Errorf("context type %v is not btc tx", sc.contextType)
+	}
+	if len(r.BtcNetworks) > 0 && !containsFold(r.BtcNetworks, b.network) {
+		return fmt.Errorf("network %v not allowed", b.network)
+	}
+	var totalIn, totalOut, value int64
+	for _, amount := range b.amounts {
+		totalIn += amount
+	}
+	for i, txOut := range b.tx.TxOut {
+		totalOut += txOut.Value
+		if bytes.Equal(txOut.PkScript, b.signer.pkScript) {
+			continue
+		}
+		value += txOut.Value
+		if len(r.BtcReceivers) == 0 {
+			continue
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, b.signer.params)
+		if err != nil || len(addrs) != 1 || !containsString(r.BtcReceivers, addrs[0].EncodeAddress()) {
+			return fmt.Errorf("receiver of output %v not allowed", i)
+		}
+	}
+	if r.maxBtcValue != nil && big.NewInt(value).Cmp(r.maxBtcValue) > 0 {
+		return fmt.Errorf("btc value %v exceeds max value %v", value, r.maxBtcValue)
+	}
+	if fee := totalIn - totalOut; r.maxBtcFee != nil && big.NewInt(fee).Cmp(r.maxBtcFee) > 0 {
+		return fmt.Errorf("btc fee %v exceeds max fee %v", fee, r.maxBtcFee)
+	}
+	return nil
+}
+
+func (a *policyArgRule) match(value interface{}) error {
+	if len(a.In) > 0 && !containsFold(a.In, formatABIValue(value)) {
+		return fmt.Errorf("value %v not allowed", formatABIValue(value))
+	}
+	if a.min == nil && a.max == nil {
+		return nil
+	}
+	bi, ok := abiValueToBigInt(value)
+	if !ok {
+		return fmt.Errorf("value %v is not integer", value)
+	}
+	if a.min != nil && bi.Cmp(a.min) < 0 {
+		return fmt.Errorf("value %v is less than %v", bi, a.min)
+	}
+	if a.max != nil && bi.Cmp(a.max) > 0 {
+		return fmt.Errorf("value %v is greater than %v", bi, a.max)
+	}
+	return nil
+}
+
+func formatABIValue(value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.String()
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func abiValueToBigInt(value interface{}) (*big.Int, bool) {
+	if bi, ok := value.(*big.Int); ok {
+		return bi, true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), true
+	default:
+		return nil, false
+	}
+}
+
+func containsFold(list []string, item string) bool {
+	for _, elem := range list {
+		if strings.EqualFold(elem, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString is case sensitive as base58 addresses are
+func containsString(list []string, item string) bool {
+	for _, elem := range list {
+		if elem == item {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBigInt(list []*big.Int, item *big.Int) bool {
+	for _, elem := range list {
+		if elem.Cmp(item) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWithdrawFeeSignInfo(t *testing.T, to common.Address, value *big.Int, input []byte) *mpcrpc.SignInfoData {
+	chainID := big.NewInt(56)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 100000, To: &to, Value: value, Data: input})
+	txJSON, err := json.Marshal(tx)
+	assert.Nil(t, err)
+	msgHash := types.LatestSignerForChainID(chainID).Hash(tx)
+
+	initiatorKey, _ := crypto.HexToECDSA("0000000000000000000000000000000000000000000000000000000000000001")
+	signature, err := crypto.Sign(msgHash.Bytes(), initiatorKey)
+	assert.Nil(t, err)
+
+	return &mpcrpc.SignInfoData{
+		Key:        "0x01",
+		MsgHash:    []string{msgHash.String()},
+		MsgContext: []string{"withdrawfee", string(txJSON), chainID.String(), hex.EncodeToString(signature)},
+	}
+}
+
+func TestSignPolicy(t *testing.T) {
+	policy, err := loadSignPolicy("../../policy-example.toml")
+	assert.Nil(t, err)
+
+	// initiator of private key 1 is 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf
+	policy.Rules[0].Initiators = []string{"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"}
+	policy.Rules[1].Initiators = policy.Rules[0].Initiators
+
+	receiver := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	transfer := func(to common.Address, amount *big.Int) []byte {
+		input := common.FromHex("0xa9059cbb")
+		input = append(input, common.LeftPadBytes(to.Bytes(), 32)...)
+		return append(input, common.LeftPadBytes(amount.Bytes(), 32)...)
+	}
+
+	decision := policy.evaluate(newTestWithdrawFeeSignInfo(t, receiver, big.NewInt(100), nil))
+	assert.Equal(t, policyAgree, decision.Outcome)
+	assert.Equal(t, "withdraw native fee", decision.Rule)
+
+	decision = policy.evaluate(newTestWithdrawFeeSignInfo(t, token, big.NewInt(0), transfer(receiver, big.NewInt(100))))
+	assert.Equal(t, policyAgree, decision.Outcome)
+	assert.Equal(t, "withdraw token fee", decision.Rule)
+
+	// exceeds max amount
+	amount, _ := new(big.Int).SetString("1000000000000000000001", 10)
+	decision = policy.evaluate(newTestWithdrawFeeSignInfo(t, token, big.NewInt(0), transfer(receiver, amount)))
+	assert.Equal(t, policyDisagree, decision.Outcome)
+	assert.Equal(t, "other withdraw fee", decision.Rule)
+
+	// not allowed receiver
+	decision = policy.evaluate(newTestWithdrawFeeSignInfo(t, token, big.NewInt(0), transfer(token, big.NewInt(100))))
+	assert.Equal(t, policyDisagree, decision.Outcome)
+
+	// message hash mismatch
+	info := newTestWithdrawFeeSignInfo(t, receiver, big.NewInt(100), nil)
+	info.MsgHash[0] = common.Hash{}.String()
+	decision = policy.evaluate(info)
+	assert.Equal(t, policyDisagree, decision.Outcome)
+
+	// no rule matches
+	decision = policy.evaluate(&mpcrpc.SignInfoData{MsgContext: []string{"plaintext", "hello"}})
+	assert.Equal(t, policyIgnore, decision.Outcome)
+}
+
+func TestSignPolicyBatch(t *testing.T) {
+	policy, err := loadSignPolicy("../../policy-example.toml")
+	assert.Nil(t, err)
+	policy.Rules[0].Initiators = []string{"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"}
+
+	receiver := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	agreed := newTestWithdrawFeeSignInfo(t, receiver, big.NewInt(100), nil)
+	decision := policy.evaluate(newTestBatchSignInfo(t, agreed, agreed))
+	assert.Equal(t, policyAgree, decision.Outcome)
+	assert.Equal(t, "batch[withdraw native fee, withdraw native fee]", decision.Rule)
+
+	// every entry must be agreed
+	other := newTestWithdrawFeeSignInfo(t, common.HexToAddress("0x4444444444444444444444444444444444444444"), big.NewInt(100), nil)
+	decision = policy.evaluate(newTestBatchSignInfo(t, agreed, other))
+	assert.Equal(t, policyDisagree, decision.Outcome)
+	plaintext := &mpcrpc.SignInfoData{MsgHash: []string{common.Hash{}.String()}, MsgContext: []string{"plaintext", "hello"}}
+	decision = policy.evaluate(newTestBatchSignInfo(t, agreed, plaintext))
+	assert.Equal(t, policyIgnore, decision.Outcome)
+
+	// agree rules can not match non-tx contexts
+	_, err = newSignPolicyOfRules(&policyRule{Outcome: policyAgree, ContextTypes: []string{"batch"}})
+	assert.ErrorIs(t, err, errWrongPolicy)
+	_, err = newSignPolicyOfRules(&policyRule{Outcome: policyAgree, ContextTypes: []string{"plaintext"}})
+	assert.ErrorIs(t, err, errWrongPolicy)
+	policy, err = newSignPolicyOfRules(&policyRule{Outcome: policyAgree})
+	assert.Nil(t, err)
+	assert.Equal(t, policyIgnore, policy.evaluate(plaintext).Outcome)
+	assert.Equal(t, policyIgnore, policy.evaluate(newTestBatchSignInfo(t, plaintext)).Outcome)
+}
+
+func TestSignPolicyBtcTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	pubkey := hexutil.Encode(crypto.FromECDSAPub(&key.PublicKey))
+	signer, err := newBtcSigner("btc-testnet", btcAddressTypeP2WPKH, pubkey)
+	assert.Nil(t, err)
+	receiverKey, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	receiver, err := newBtcSigner("btc-testnet", btcAddressTypeP2WPKH, hexutil.Encode(crypto.FromECDSAPub(&receiverKey.PublicKey)))
+	assert.Nil(t, err)
+
+	newBtcSignInfo := func(value, change int64) *mpcrpc.SignInfoData {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{2}, 1), nil, nil))
+		tx.AddTxOut(wire.NewTxOut(value, receiver.pkScript))
+		tx.AddTxOut(wire.NewTxOut(change, signer.pkScript))
+		amounts := []int64{60000, 40000}
+		rawTx, errf := encodeBtcTx(tx)
+		assert.Nil(t, errf)
+		hashes, errf := signer.sigHashes(tx, amounts)
+		assert.Nil(t, errf)
+		btcContext, errf := json.Marshal(&btcTxContext{Network: "btc-testnet", AddressType: btcAddressTypeP2WPKH, RawTx: rawTx, Amounts: amounts})
+		assert.Nil(t, errf)
+		return &mpcrpc.SignInfoData{Key: "0x01", PubKey: pubkey, MsgHash: hashes, MsgContext: []string{"btctx", string(btcContext)}}
+	}
+
+	policy, err := newSignPolicyOfRules(&policyRule{
+		Outcome:      policyAgree,
+		ContextTypes: []string{"btctx"},
+		BtcNetworks:  []string{"btc-testnet"},
+		BtcReceivers: []string{receiver.address.EncodeAddress()},
+		MaxBtcValue:  "50000",
+		MaxBtcFee:    "1000",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, policyAgree, policy.evaluate(newBtcSignInfo(50000, 49000)).Outcome)
+	assert.Equal(t, policyIgnore, policy.evaluate(newBtcSignInfo(50001, 49000)).Outcome, "exceeds max value")
+	assert.Equal(t, policyIgnore, policy.evaluate(newBtcSignInfo(50000, 48000)).Outcome, "exceeds max fee")
+
+	// batch entry of btctx has a message hash per input
+	batch := newTestBatchSignInfo(t, newBtcSignInfo(50000, 49000), newBtcSignInfo(40000, 59000))
+	assert.Equal(t, 4, len(batch.MsgHash))
+	assert.Nil(t, verifySignInfo(batch))
+	assert.Equal(t, policyAgree, policy.evaluate(batch).Outcome)
+	batch.MsgHash[0], batch.MsgHash[1] = batch.MsgHash[1], batch.MsgHash[0]
+	assert.NotNil(t, verifySignInfo(batch))
+	batch.MsgHash = batch.MsgHash[:3]
+	assert.NotNil(t, verifySignInfo(batch))
+
+	policy.Rules[0].BtcReceivers = []string{signer.address.EncodeAddress()}
+	assert.Equal(t, policyIgnore, policy.evaluate(newBtcSignInfo(50000, 49000)).Outcome, "receiver not allowed")
+
+	// value to receiver and fee are spent, change is excluded
+	sc, err := parseSignContext(newBtcSignInfo(50000, 49000))
+	assert.Nil(t, err)
+	spends, err := policy.spendsOfContext(sc)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(spends))
+	assert.Equal(t, receiver.address.EncodeAddress(), spends[0].Receiver)
+	assert.Equal(t, big.NewInt(50000), spends[0].Amount)
+	assert.Equal(t, big.NewInt(1000), spends[1].Amount)
+}
+
+func newTestBatchSignInfo(t *testing.T, entries ...*mpcrpc.SignInfoData) *mpcrpc.SignInfoData {
+	batch := &mpcrpc.SignInfoData{Key: "0x01"}
+	var batchEntries []*batchSignEntry
+	for _, entry := range entries {
+		batch.PubKey = entry.PubKey
+		batch.MsgHash = append(batch.MsgHash, entry.MsgHash...)
+		batchEntries = append(batchEntries, &batchSignEntry{MsgHashes: entry.MsgHash, MsgContext: entry.MsgContext})
+	}
+	data, err := json.Marshal(batchEntries)
+	assert.Nil(t, err)
+	batch.MsgContext = []string{"batch", string(data)}
+	return batch
+}
+
+func newSignPolicyOfRules(rules ...*policyRule) (*signPolicy, error) {
+	policy := &signPolicy{Rules: rules}
+	return policy, policy.init()
+}
+
+func TestSignPolicyLimits(t *testing.T) {
+	policy, err := loadSignPolicy("../../policy-example.toml")
+	assert.Nil(t, err)
+	policy.Rules[0].Initiators = []string{"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"}
+	policy.Rules[1].Initiators = policy.Rules[0].Initiators
+
+	storeFile := filepath.Join(t.TempDir(), "limits.json")
+	store, err := loadLimitStore(storeFile)
+	assert.Nil(t, err)
+
+	receiver := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	eth := big.NewInt(1e18)
+	transfer := func(amount *big.Int) []byte {
+		input := common.FromHex("0xa9059cbb")
+		input = append(input, common.LeftPadBytes(receiver.Bytes(), 32)...)
+		return append(input, common.LeftPadBytes(amount.Bytes(), 32)...)
+	}
+
+	now := time.Now()
+	var count int
+	apply := func(to common.Address, value *big.Int, input []byte) error {
+		count++
+		info := newTestWithdrawFeeSignInfo(t, to, value, input)
+		info.Key = fmt.Sprintf("0x%x", count)
+		decision := policy.evaluate(info)
+		assert.Equal(t, policyAgree, decision.Outcome)
+		rec, errf := policy.checkLimits(store, info.Key, decision.context, now)
+		if errf != nil {
+			return errf
+		}
+		return store.add(rec, now.Add(-policy.maxPeriod()).Unix())
+	}
+
+	// daily native limit is 10 eth
+	assert.Nil(t, apply(receiver, new(big.Int).Mul(big.NewInt(6), eth), nil))
+	assert.ErrorIs(t, apply(receiver, new(big.Int).Mul(big.NewInt(6), eth), nil), errLimitExceeded)
+
+	// hourly token limit is 2000 tokens
+	amount := new(big.Int).Mul(big.NewInt(800), eth)
+	assert.Nil(t, apply(token, big.NewInt(0), transfer(amount)))
+	assert.Nil(t, apply(token, big.NewInt(0), transfer(amount)))
+	assert.ErrorIs(t, apply(token, big.NewInt(0), transfer(amount)), errLimitExceeded)
+
+	// records out of period are not counted
+	now = now.Add(time.Hour + time.Second)
+	assert.Nil(t, apply(token, big.NewInt(0), transfer(amount)))
+
+	// records are persisted
+	store, err = loadLimitStore(storeFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(store.Records))
+
+	// rate limit
+	policy.MaxAgreesPerHour = 1
+	assert.ErrorIs(t, apply(receiver, big.NewInt(1), nil), errLimitExceeded)
+
+	// max fee (gas * gas price) is counted, and batch entries are summed
+	info := newTestWithdrawFeeSignInfo(t, receiver, big.NewInt(100), nil)
+	sc, err := parseSignContext(info)
+	assert.Nil(t, err)
+	spends, err := policy.spendsOfContext(sc)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(spends))
+	assert.Equal(t, big.NewInt(100000), spends[0].Amount)
+	spends, err = policy.spendsOfContext(&signContext{contextType: "batch", entries: []*signContext{sc, sc}})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(spends))
+
+	// unknown spendings are refused
+	_, err = policy.spendsOfContext(&signContext{contextType: "plaintext"})
+	assert.ErrorIs(t, err, errLimitExceeded)
+
+	// router calls may move limited tokens on chain with token limits
+	router := common.HexToAddress("0x5555555555555555555555555555555555555555")
+	routerCall := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 100000, To: &router, Data: common.FromHex("0xedbdf5e2")})
+	_, err = policy.spendsOfContext(&signContext{contextType: "ethtx", chainID: big.NewInt(56), tx: routerCall})
+	assert.ErrorIs(t, err, errLimitExceeded)
+	_, err = policy.spendsOfContext(&signContext{contextType: "ethtx", chainID: big.NewInt(1), tx: routerCall})
+	assert.Nil(t, err)
+
+	// max fee of rule
+	policy.Rules[0].maxFee = big.NewInt(99999)
+	decision := policy.evaluate(info)
+	assert.Equal(t, policyDisagree, decision.Outcome)
+	assert.Equal(t, "other withdraw fee", decision.Rule)
+}
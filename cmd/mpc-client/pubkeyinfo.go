@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	pubkeyInfoCommand = &cli.Command{
+		Action:      pubkeyInfo,
+		Name:        "pubkeyinfo",
+		Usage:       "show compressed key and chain addresses of mpc public key",
+		ArgsUsage:   "",
+		Description: ``,
+		Flags: []cli.Flag{
+			pubkeyFlag,
+			signTypeFlag,
+			btcNetworksFlag,
+		},
+	}
+)
+
+func pubkeyInfo(ctx *cli.Context) error {
+	utils.SetLogger(ctx)
+	info, err := mpcrpc.GetPublicKeyInfo(ctx.String(signTypeFlag.Name), ctx.String(pubkeyFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("key type is", info.KeyType)
+	fmt.Println("public key is", info.PublicKey)
+	if info.KeyType == mpcrpc.KeyTypeED25519 {
+		fmt.Println("base58 address is", info.Base58Address)
+		return nil
+	}
+
+	fmt.Println("compressed public key is", info.CompressedKey)
+	fmt.Println("evm address is", info.EVMAddress)
+	for _, network := range ctx.StringSlice(btcNetworksFlag.Name) {
+		if _, exist := btcNetworks[network]; !exist {
+			return fmt.Errorf("%w '%v'", errUnknownBtcNetwork, network)
+		}
+		for _, addrType := range []string{btcAddressTypeP2PKH, btcAddressTypeP2WPKH} {
+			signer, errf := newBtcSigner(network, addrType, info.PublicKey)
+			if errf != nil {
+				// eg. network does not support segwit
+				continue
+			}
+			fmt.Printf("%v %v address is %v\n", network, addrType, signer.address.EncodeAddress())
+		}
+	}
+	return nil
+}
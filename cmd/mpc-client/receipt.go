@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli/v2"
+)
+
+const receiptPollInterval = 3 * time.Second
+
+var (
+	errTxReverted         = errors.New("tx is reverted")
+	errWaitReceiptTimeout = errors.New("wait tx receipt timeout")
+
+	panicSelector = common.FromHex("0x4e487b71") // Panic(uint256)
+)
+
+type waitReceiptArgs struct {
+	wait          bool
+	confirmations uint64
+	timeout       time.Duration
+}
+
+var waitArgs waitReceiptArgs
+
+func checkWaitReceiptArguments(ctx *cli.Context) {
+	waitArgs.wait = ctx.Bool(waitFlag.Name)
+	waitArgs.confirmations = ctx.Uint64(confirmationsFlag.Name)
+	if waitArgs.confirmations == 0 {
+		waitArgs.confirmations = 1
+	}
+	waitArgs.timeout = time.Duration(ctx.Uint64(waitTimeoutFlag.Name)) * time.Second
+}
+
+// waitTxReceipt polls the receipt of tx from all gateways until it is
+// confirmed by enough blocks, returns error if the tx is reverted or timeout.
+func waitTxReceipt(tx *types.Transaction, from common.Address) error {
+	txHash := tx.Hash()
+	log.Info("start wait tx receipt", "txHash", txHash.String(), "confirmations", waitArgs.confirmations, "timeout", waitArgs.timeout.String())
+	deadline := time.Now().Add(waitArgs.timeout)
+	for {
+		receipt := getTxReceipt(txHash)
+		if receipt != nil {
+			latest, err := getLatestBlockNumber()
+			if err == nil && latest >= receipt.BlockNumber.Uint64() {
+				confirmations := latest - receipt.BlockNumber.Uint64() + 1
+				log.Info("tx is mined", "txHash", txHash.String(), "blockNumber", receipt.BlockNumber, "confirmations", confirmations)
+				if confirmations >= waitArgs.confirmations {
+					return reportTxReceipt(tx, from, receipt)
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w, txHash %v", errWaitReceiptTimeout, txHash.String())
+		}
+		if !sleepOrCanceled(receiptPollInterval) {
+			return bgCtx.Err()
+		}
+	}
+}
+
+func getTxReceipt(txHash common.Hash) *types.Receipt {
+	for _, ethClient := range ethClients {
+		receipt, err := ethClient.cli.TransactionReceipt(bgCtx, txHash)
+		if err != nil {
+			if !errors.Is(err, ethereum.NotFound) {
+				log.Warn("get tx receipt failed", "txHash", txHash.String(), "url", ethClient.url, "err", err)
+			}
+			continue
+		}
+		return receipt
+	}
+	return nil
+}
+
+func getLatestBlockNumber() (maxNumber uint64, err error) {
+	var success bool
+	for _, ethClient := range ethClients {
+		number, errf := ethClient.cli.BlockNumber(bgCtx)
+		if errf != nil {
+			log.Warn("get latest block number failed", "url", ethClient.url, "err", errf)
+			err = errf
+			continue
+		}
+		success = true
+		if number > maxNumber {
+			maxNumber = number
+		}
+	}
+	if success {
+		return maxNumber, nil
+	}
+	return 0, err
+}
+
+func reportTxReceipt(tx *types.Transaction, from common.Address, receipt *types.Receipt) error {
+	status := "success"
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		status = "reverted"
+	}
+	fmt.Printf("tx %v is %v\n", receipt.TxHash.String(), status)
+	fmt.Printf("block number is %v, block hash is %v\n", receipt.BlockNumber, receipt.BlockHash.String())
+	fmt.Printf("gas used is %v, gas limit is %v\n", receipt.GasUsed, tx.Gas())
+	if receipt.ContractAddress != (common.Address{}) {
+		fmt.Printf("contract address is %v\n", receipt.ContractAddress.String())
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil
+	}
+	reason := getRevertReason(tx, from, receipt.BlockNumber)
+	if reason != "" {
+		fmt.Printf("revert reason is %v\n", reason)
+		return fmt.Errorf("%w, reason: %v", errTxReverted, reason)
+	}
+	return errTxReverted
+}
+
+// getRevertReason replays the tx as call on the state of the block before
+// the tx is mined, it's an approximation if other txs in the block change the state.
+func getRevertReason(tx *types.Transaction, from common.Address, blockNumber *big.Int) string {
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	callBlock := new(big.Int).Sub(blockNumber, big.NewInt(1))
+	for _, ethClient := range ethClients {
+		_, err := ethClient.cli.CallContract(bgCtx, msg, callBlock)
+		if err == nil {
+			continue
+		}
+		var dataErr interface{ ErrorData() interface{} }
+		if errors.As(err, &dataErr) {
+			if data, ok := dataErr.ErrorData().(string); ok {
+				if reason, errf := decodeRevertData(common.FromHex(data)); errf == nil {
+					return reason
+				}
+			}
+		}
+		return err.Error()
+	}
+	return ""
+}
+
+func decodeRevertData(data []byte) (string, error) {
+	if len(data) == 4+32 && bytes.Equal(data[:4], panicSelector) {
+		return fmt.Sprintf("panic code %v", hexutil.EncodeBig(new(big.Int).SetBytes(data[4:]))), nil
+	}
+	return abi.UnpackRevert(data)
+}
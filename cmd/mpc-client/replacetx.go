@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	cancelTxGasLimit = 21000
+
+	// replace modes in message context
+	replaceModeSpeedUp = "speedup"
+	replaceModeCancel  = "cancel"
+)
+
+var (
+	replaceTxFlags = []cli.Flag{
+		pubkeyFlag,
+		gidFlag,
+		thresholdFlag,
+		signModeFlag,
+		signMemoFlag,
+		mpcServerFlag,
+		mpcKeystoreFlag,
+		mpcPasswordFlag,
+		signTypeFlag,
+		apiPrefixFlag,
+		rpcTimeoutFlag,
+		signTimeoutFlag,
+		gatewaysFlag,
+		chainIDFlag,
+		txHashFlag,
+		bumpPercentFlag,
+		maxGasFeeFlag,
+		maxTotalFeeFlag,
+		dryrunFlag,
+		waitFlag,
+		confirmationsFlag,
+		waitTimeoutFlag,
+	}
+
+	speedUpTxCommand = &cli.Command{
+		Action:      speedUpTx,
+		Name:        "speeduptx",
+		Usage:       "speed up pending tx by resending it with higher fee",
+		ArgsUsage:   "",
+		Description: ``,
+		Flags:       replaceTxFlags,
+	}
+
+	cancelTxCommand = &cli.Command{
+		Action:      cancelTx,
+		Name:        "canceltx",
+		Usage:       "cancel pending tx by sending zero value tx to self with the same nonce and higher fee",
+		ArgsUsage:   "",
+		Description: ``,
+		Flags:       replaceTxFlags,
+	}
+)
+
+type replaceTxArgs struct {
+	txHash      common.Hash
+	bumpPercent uint64
+}
+
+var replaceArgs replaceTxArgs
+
+func speedUpTx(ctx *cli.Context) error {
+	return replaceTx(ctx, false)
+}
+
+func cancelTx(ctx *cli.Context) error {
+	return replaceTx(ctx, true)
+}
+
+func checkReplaceTxArguments(ctx *cli.Context) (err error) {
+	txArgs.gateways = ctx.StringSlice(gatewaysFlag.Name)
+	txArgs.dryrun = ctx.Bool(dryrunFlag.Name)
+	checkWaitReceiptArguments(ctx)
+
+	var ok bool
+	nodeChainIDStr := ctx.String(chainIDFlag.Name)
+	txArgs.chainID, ok = new(big.Int).SetString(nodeChainIDStr, 0)
+	if !ok {
+		return fmt.Errorf("wrong chain Id %v", nodeChainIDStr)
+	}
+
+	txHashStr := ctx.String(txHashFlag.Name)
+	if !strings.EqualFold(common.HexToHash(txHashStr).String(), txHashStr) {
+		return fmt.Errorf("wrong tx hash %v", txHashStr)
+	}
+	replaceArgs.txHash = common.HexToHash(txHashStr)
+
+	replaceArgs.bumpPercent = ctx.Uint64(bumpPercentFlag.Name)
+	if replaceArgs.bumpPercent == 0 {
+		return errors.New("bump percent must be positive")
+	}
+
+	gasOptions.maxGasFee, err = parseBigIntArgument("max gas fee", ctx.String(maxGasFeeFlag.Name))
+	if err != nil {
+		return err
+	}
+	gasOptions.maxTotalFee, err = parseBigIntArgument("max total fee", ctx.String(maxTotalFeeFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	log.Info("check arguments pass")
+	return nil
+}
+
+func replaceTx(ctx *cli.Context, isCancel bool) (err error) {
+	utils.SetLogger(ctx)
+	mpcCfg.NeedKeyStore = true
+	err = checkAndInitMpcConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+	err = checkECDSAKeyType()
+	if err != nil {
+		return err
+	}
+	err = checkReplaceTxArguments(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = dailGateways(txArgs.gateways)
+	if err != nil {
+		return err
+	}
+
+	origTx, err := getPendingTransaction(replaceArgs.txHash)
+	if err != nil {
+		return err
+	}
+	log.Printf("the original tx is")
+	_ = printTx(origTx, true)
+
+	txArgs.from, err = types.Sender(types.LatestSignerForChainID(txArgs.chainID), origTx)
+	if err != nil {
+		log.Error("get sender of original tx failed", "err", err)
+		return err
+	}
+
+	err = fillReplaceTxArguments(origTx, isCancel)
+	if err != nil {
+		return err
+	}
+
+	rawTx := buildRawTx(origTx.Nonce())
+	log.Info("create replace tx success", "type", rawTx.Type(), "isCancel", isCancel)
+	_ = printTx(rawTx, true)
+
+	mode := replaceModeSpeedUp
+	if isCancel {
+		mode = replaceModeCancel
+	}
+	// the signed original tx is carried for the acceptors to verify the replacement
+	origTxJSON, err := json.Marshal(origTx)
+	if err != nil {
+		return err
+	}
+	return mpcSignAndSendTx(rawTx, "replacetx", replaceArgs.txHash.String(), string(origTxJSON), mode)
+}
+
+// getPendingTransaction get tx by hash from gateways, and check it's still pending
+func getPendingTransaction(txHash common.Hash) (tx *types.Transaction, err error) {
+	for _, ethClient := range ethClients {
+		var isPending bool
+		tx, isPending, err = ethClient.cli.TransactionByHash(bgCtx, txHash)
+		if err != nil {
+			log.Warn("get tx by hash failed", "txHash", txHash.String(), "url", ethClient.url, "err", err)
+			continue
+		}
+		if !isPending {
+			return nil, fmt.Errorf("tx %v is already mined", txHash.String())
+		}
+		return tx, nil
+	}
+	return nil, err
+}
+
+// fillReplaceTxArguments fills txArgs from the original tx with bumped fees,
+// cancel tx is a zero value tx to self without input data.
+func fillReplaceTxArguments(origTx *types.Transaction, isCancel bool) error {
+	txArgs.createContract = origTx.To() == nil
+	if origTx.To() != nil {
+		txArgs.to = *origTx.To()
+	}
+	txArgs.value = origTx.Value()
+	txArgs.input = origTx.Data()
+	txArgs.gasLimit = origTx.Gas()
+	txArgs.accessList = origTx.AccessList()
+	if isCancel {
+		txArgs.createContract = false
+		txArgs.to = txArgs.from
+		txArgs.value = big.NewInt(0)
+		txArgs.input = nil
+		txArgs.gasLimit = cancelTxGasLimit
+		txArgs.accessList = nil
+	}
+
+	txArgs.gasPrice = nil
+	txArgs.maxFeePerGas = nil
+	txArgs.maxPriorityFeePerGas = nil
+	if origTx.Type() == types.DynamicFeeTxType {
+		tip := bumpFee(origTx.GasTipCap())
+		if suggested, err := suggestGasTipCap(); err == nil && suggested.Cmp(tip) > 0 {
+			tip = suggested
+		}
+		feeCap := bumpFee(origTx.GasFeeCap())
+		if tip.Cmp(feeCap) > 0 {
+			feeCap = tip
+		}
+		txArgs.maxPriorityFeePerGas = tip
+		txArgs.maxFeePerGas = feeCap
+		log.Info("bump tx fee", "oldTip", origTx.GasTipCap(), "newTip", tip, "oldFeeCap", origTx.GasFeeCap(), "newFeeCap", feeCap)
+	} else {
+		gasPrice := bumpFee(origTx.GasPrice())
+		if suggested, err := suggestGasPrice(); err == nil && suggested.Cmp(gasPrice) > 0 {
+			gasPrice = suggested
+		}
+		txArgs.gasPrice = gasPrice
+		log.Info("bump tx fee", "oldGasPrice", origTx.GasPrice(), "newGasPrice", gasPrice)
+	}
+	return checkMaxGasFee()
+}
+
+// verifyReplaceTx checks the replacement has the same nonce and sender and higher fees,
+// speed up tx has the same to, value and data, cancel tx is a zero value tx to self.
+func verifyReplaceTx(origTx, tx *types.Transaction, sender common.Address, mode string) error {
+	if tx.Nonce() != origTx.Nonce() {
+		return fmt.Errorf("replace tx nonce %v mismatch with original nonce %v", tx.Nonce(), origTx.Nonce())
+	}
+	if tx.GasTipCap().Cmp(origTx.GasTipCap()) <= 0 || tx.GasFeeCap().Cmp(origTx.GasFeeCap()) <= 0 {
+		return fmt.Errorf("replace tx fees (tip %v, fee cap %v) are not higher than original (tip %v, fee cap %v)",
+			tx.GasTipCap(), tx.GasFeeCap(), origTx.GasTipCap(), origTx.GasFeeCap())
+	}
+	switch mode {
+	case replaceModeSpeedUp:
+		if !equalTxTo(tx.To(), origTx.To()) || tx.Value().Cmp(origTx.Value()) != 0 || !bytes.Equal(tx.Data(), origTx.Data()) {
+			return errors.New("speed up tx has different to, value or data from original")
+		}
+	case replaceModeCancel:
+		if tx.To() == nil || *tx.To() != sender || tx.Value().Sign() != 0 || len(tx.Data()) != 0 {
+			return errors.New("cancel tx is not a zero value tx to self")
+		}
+	default:
+		return fmt.Errorf("unknown replace mode '%v'", mode)
+	}
+	return nil
+}
+
+func equalTxTo(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// printReplaceTxDiff prints the fields of original and replace tx side by side
+func printReplaceTxDiff(origTx, tx *types.Transaction) {
+	formatTo := func(to *common.Address) string {
+		if to == nil {
+			return "create contract"
+		}
+		return to.String()
+	}
+	fields := []struct {
+		name       string
+		orig, repl interface{}
+	}{
+		{"type", origTx.Type(), tx.Type()},
+		{"nonce", origTx.Nonce(), tx.Nonce()},
+		{"to", formatTo(origTx.To()), formatTo(tx.To())},
+		{"value", origTx.Value(), tx.Value()},
+		{"data", hexutil.Encode(origTx.Data()), hexutil.Encode(tx.Data())},
+		{"gas", origTx.Gas(), tx.Gas()},
+		{"gasTipCap", origTx.GasTipCap(), tx.GasTipCap()},
+		{"gasFeeCap", origTx.GasFeeCap(), tx.GasFeeCap()},
+	}
+	for _, f := range fields {
+		orig, repl := fmt.Sprint(f.orig), fmt.Sprint(f.repl)
+		if orig == repl {
+			fmt.Printf("  %-10v %v\n", f.name, orig)
+		} else {
+			fmt.Printf("* %-10v %v => %v\n", f.name, orig, repl)
+		}
+	}
+}
+
+// bumpFee increases fee by bump percent, at least by 1 Wei
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+replaceArgs.bumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/anyswap/mpc-client/mpcrpc/client"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/urfave/cli/v2"
+)
+
+const btcRPCTimeout = 60 // seconds
+
+var (
+	sendBtcTxCommand = &cli.Command{
+		Action:    sendBtcTx,
+		Name:      "sendbtctx",
+		Usage:     "send bitcoin-family (btc, ltc, doge) transaction",
+		ArgsUsage: "",
+		Description: `
+spend the specified utxos of the p2pkh or p2wpkh address of mpc public key,
+pay 'value' to 'to' address and the change back to the mpc address.
+the raw txs of p2pkh utxos are fetched by 'getrawtransaction' rpc of the
+gateways if not specified, the acceptors verify input amounts with them.
+the signed tx is sent by 'sendrawtransaction' rpc of the gateways.`,
+		Flags: []cli.Flag{
+			pubkeyFlag,
+			gidFlag,
+			thresholdFlag,
+			signModeFlag,
+			signMemoFlag,
+			mpcServerFlag,
+			mpcKeystoreFlag,
+			mpcPasswordFlag,
+			signTypeFlag,
+			apiPrefixFlag,
+			rpcTimeoutFlag,
+			signTimeoutFlag,
+			gatewaysFlag,
+			btcNetworkFlag,
+			btcAddressTypeFlag,
+			utxosFlag,
+			toAddrFlag,
+			valueFlag,
+			btcFeeFlag,
+			dryrunFlag,
+		},
+	}
+)
+
+// btcUtxo the raw tx of utxo is fetched from gateways if not specified,
+// it's required for p2pkh inputs as their amounts are verified by acceptors.
+type btcUtxo struct {
+	TxID   string `json:"txid"`
+	Vout   uint32 `json:"vout"`
+	Amount int64  `json:"amount"`
+	RawTx  string `json:"rawTx,omitempty"`
+}
+
+type sendBtcTxArgs struct {
+	network string
+	signer  *btcSigner
+	utxos   []*btcUtxo
+	to      btcutil.Address
+	value   int64
+	fee     int64
+}
+
+var btcTxArgs sendBtcTxArgs
+
+func checkSendBtcTxArguments(ctx *cli.Context) (err error) {
+	txArgs.gateways = ctx.StringSlice(gatewaysFlag.Name)
+	txArgs.dryrun = ctx.Bool(dryrunFlag.Name)
+
+	btcTxArgs.network = ctx.String(btcNetworkFlag.Name)
+	btcTxArgs.signer, err = newBtcSigner(btcTxArgs.network, ctx.String(btcAddressTypeFlag.Name), mpcPublicKey)
+	if err != nil {
+		return err
+	}
+
+	toAddrStr := ctx.String(toAddrFlag.Name)
+	btcTxArgs.to, err = btcutil.DecodeAddress(toAddrStr, btcTxArgs.signer.params)
+	if err != nil || !btcTxArgs.to.IsForNet(btcTxArgs.signer.params) {
+		return fmt.Errorf("wrong to address %v", toAddrStr)
+	}
+
+	valueStr := ctx.String(valueFlag.Name)
+	btcTxArgs.value, err = strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || btcTxArgs.value < btcDustAmount {
+		return fmt.Errorf("wrong value %v, must be satoshis not less than %v", valueStr, btcDustAmount)
+	}
+	btcTxArgs.fee = ctx.Int64(btcFeeFlag.Name)
+	if btcTxArgs.fee <= 0 {
+		return fmt.Errorf("wrong fee %v", btcTxArgs.fee)
+	}
+
+	utxosFile := ctx.String(utxosFlag.Name)
+	if utxosFile == "" {
+		return errors.New("must specify utxos file")
+	}
+	data, err := ioutil.ReadFile(utxosFile)
+	if err != nil {
+		return fmt.Errorf("read utxos file failed, %w", err)
+	}
+	err = json.Unmarshal(data, &btcTxArgs.utxos)
+	if err != nil {
+		return fmt.Errorf("wrong utxos file %v, %w", utxosFile, err)
+	}
+	if len(btcTxArgs.utxos) == 0 {
+		return fmt.Errorf("wrong utxos file %v, no utxos", utxosFile)
+	}
+
+	log.Info("check arguments pass", "from", btcTxArgs.signer.address.EncodeAddress())
+	return nil
+}
+
+func sendBtcTx(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	mpcCfg.NeedKeyStore = true
+	err = checkAndInitMpcConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+	err = checkECDSAKeyType()
+	if err != nil {
+		return err
+	}
+	err = checkSendBtcTxArguments(ctx)
+	if err != nil {
+		return err
+	}
+
+	signer := btcTxArgs.signer
+	tx, amounts, err := buildBtcTx()
+	if err != nil {
+		return err
+	}
+	rawTx, err := encodeBtcTx(tx)
+	if err != nil {
+		return err
+	}
+	log.Info("create raw tx success", "inputs", len(tx.TxIn), "outputs", len(tx.TxOut))
+	signer.printBtcTx(tx, amounts)
+
+	prevTxs, err := getBtcPrevTxs()
+	if err != nil {
+		return err
+	}
+	err = signer.checkPrevTxs(tx, amounts, prevTxs)
+	if err != nil {
+		return err
+	}
+
+	sigHashes, err := signer.sigHashes(tx, amounts)
+	if err != nil {
+		return err
+	}
+	btcContext, err := json.Marshal(&btcTxContext{
+		Network:     btcTxArgs.network,
+		AddressType: signer.addrType,
+		RawTx:       rawTx,
+		Amounts:     amounts,
+		PrevTxs:     prevTxs,
+	})
+	if err != nil {
+		return err
+	}
+	msgContext := []string{"btctx", string(btcContext)}
+	if signMemoArg != "" {
+		msgContext = append(msgContext, signMemoArg)
+	}
+
+	keyID, rsvs, err := mpcClient.DoSignContext(bgCtx, mpcPublicKey, sigHashes, msgContext)
+	if err != nil {
+		log.Error("mpc sign failed", "err", err)
+		return err
+	}
+	log.Info("mpc sign success", "keyID", keyID)
+
+	err = signer.applySignatures(tx, rsvs)
+	if err != nil {
+		return err
+	}
+	err = signer.verifyScripts(tx, amounts)
+	if err != nil {
+		return err
+	}
+	signedTx, err := encodeBtcTx(tx)
+	if err != nil {
+		return err
+	}
+	txHash := tx.TxHash().String()
+	log.Info("mpc sign tx success", "txHash", txHash)
+	fmt.Println("signed raw tx is", signedTx)
+
+	if txArgs.dryrun {
+		return nil
+	}
+	err = sendBtcRawTransaction(txArgs.gateways, signedTx)
+	if err != nil {
+		log.Error("send tx failed", "err", err)
+		return err
+	}
+	log.Info("send tx success", "txHash", txHash)
+	return nil
+}
+
+// buildBtcTx spends all utxos, and adds change output if it's not dust
+func buildBtcTx() (tx *wire.MsgTx, amounts []int64, err error) {
+	tx = wire.NewMsgTx(wire.TxVersion)
+	amounts = make([]int64, len(btcTxArgs.utxos))
+	var totalIn int64
+	for i, utxo := range btcTxArgs.utxos {
+		prevHash, errf := chainhash.NewHashFromStr(utxo.TxID)
+		if errf != nil || utxo.Amount <= 0 {
+			return nil, nil, fmt.Errorf("wrong utxo %v:%v", utxo.TxID, utxo.Vout)
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(prevHash, utxo.Vout), nil, nil))
+		amounts[i] = utxo.Amount
+		totalIn += utxo.Amount
+	}
+
+	toScript, err := txscript.PayToAddrScript(btcTxArgs.to)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx.AddTxOut(wire.NewTxOut(btcTxArgs.value, toScript))
+
+	change := totalIn - btcTxArgs.value - btcTxArgs.fee
+	switch {
+	case change < 0:
+		return nil, nil, fmt.Errorf("insufficient utxos amount %v, value %v, fee %v", totalIn, btcTxArgs.value, btcTxArgs.fee)
+	case change >= btcDustAmount:
+		tx.AddTxOut(wire.NewTxOut(change, btcTxArgs.signer.pkScript))
+	case change > 0:
+		log.Info("dust change is added to fee", "change", change)
+	}
+	return tx, amounts, nil
+}
+
+// getBtcPrevTxs returns the raw txs of utxos in order,
+// they are fetched from gateways only if required by p2pkh inputs.
+func getBtcPrevTxs() (prevTxs []string, err error) {
+	if btcTxArgs.signer.isWitness() {
+		for _, utxo := range btcTxArgs.utxos {
+			if utxo.RawTx == "" {
+				return nil, nil
+			}
+		}
+	}
+	prevTxs = make([]string, len(btcTxArgs.utxos))
+	for i, utxo := range btcTxArgs.utxos {
+		if utxo.RawTx != "" {
+			prevTxs[i] = utxo.RawTx
+			continue
+		}
+		prevTxs[i], err = getBtcRawTransaction(txArgs.gateways, utxo.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("get raw tx of utxo %v:%v failed, %w", utxo.TxID, utxo.Vout, err)
+		}
+	}
+	return prevTxs, nil
+}
+
+func getBtcRawTransaction(gateways []string, txid string) (rawTx string, err error) {
+	if len(gateways) == 0 {
+		return "", errors.New("must specify gateways")
+	}
+	for _, gateway := range gateways {
+		err = client.RPCPostWithContext(bgCtx, btcRPCTimeout, &rawTx, gateway, "getrawtransaction", txid)
+		if err == nil {
+			return rawTx, nil
+		}
+		log.Warn("get raw tx failed", "url", gateway, "txid", txid, "err", err)
+	}
+	return "", err
+}
+
+func sendBtcRawTransaction(gateways []string, signedTx string) (err error) {
+	if len(gateways) == 0 {
+		return errors.New("must specify gateways")
+	}
+	var success bool
+	for _, gateway := range gateways {
+		var txHash string
+		err = client.RPCPostWithContext(bgCtx, btcRPCTimeout, &txHash, gateway, "sendrawtransaction", signedTx)
+		if err != nil {
+			log.Warn("send tx failed", "url", gateway, "err", err)
+			continue
+		}
+		success = true
+	}
+	if success {
+		return nil
+	}
+	return err
+}
@@ -1,15 +1,14 @@
 package main
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 
 	"github.com/anyswap/mpc-client/cmd/utils"
 	"github.com/anyswap/mpc-client/log"
-	"github.com/anyswap/mpc-client/mpcrpc"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -47,8 +46,18 @@ var (
 			valueFlag,
 			gasLimitFlag,
 			gasPriceFlag,
+			maxFeePerGasFlag,
+			maxPriorityFeePerGasFlag,
 			inputFlag,
+			accessListFlag,
+			gasLimitMultiplierFlag,
+			gasPriceMultiplierFlag,
+			maxGasFeeFlag,
+			maxTotalFeeFlag,
 			dryrunFlag,
+			waitFlag,
+			confirmationsFlag,
+			waitTimeoutFlag,
 		},
 	}
 )
@@ -60,6 +69,11 @@ type sendEthTxArgs struct {
 	gasLimit uint64
 	gasPrice *big.Int
 	chainID  *big.Int
+
+	maxFeePerGas         *big.Int
+	maxPriorityFeePerGas *big.Int
+	accessList           types.AccessList
+
 	accNonce *big.Int
 	value    *big.Int
 	input    []byte
@@ -77,14 +91,13 @@ var (
 	txArgs sendEthTxArgs
 
 	ethClients []*ethClientAndURL
-
-	bgCtx = context.Background()
 )
 
 func checkSendEthTxArguments(ctx *cli.Context) (err error) {
 	txArgs.gateways = ctx.StringSlice(gatewaysFlag.Name)
 	txArgs.gasLimit = ctx.Uint64(gasLimitFlag.Name)
 	txArgs.dryrun = ctx.Bool(dryrunFlag.Name)
+	checkWaitReceiptArguments(ctx)
 
 	fromAddrStr := ctx.String(fromAddrFlag.Name)
 	if !common.IsHexAddress(fromAddrStr) {
@@ -103,13 +116,12 @@ func checkSendEthTxArguments(ctx *cli.Context) (err error) {
 		return errors.New("create contract tx forbid specify 'to' address")
 	}
 
-	var ok bool
-	gasPriceStr := ctx.String(gasPriceFlag.Name)
-	txArgs.gasPrice, ok = new(big.Int).SetString(gasPriceStr, 0)
-	if !ok {
-		return fmt.Errorf("wrong gas price %v", gasPriceStr)
+	err = checkGasPriceArguments(ctx)
+	if err != nil {
+		return err
 	}
 
+	var ok bool
 	nodeChainIDStr := ctx.String(chainIDFlag.Name)
 	txArgs.chainID, ok = new(big.Int).SetString(nodeChainIDStr, 0)
 	if !ok {
@@ -140,10 +152,116 @@ func checkSendEthTxArguments(ctx *cli.Context) (err error) {
 		}
 	}
 
+	err = checkAccessListArgument(ctx)
+	if err != nil {
+		return err
+	}
+
 	log.Info("check arguments pass")
 	return nil
 }
 
+func checkGasPriceArguments(ctx *cli.Context) (err error) {
+	gasPriceStr := ctx.String(gasPriceFlag.Name)
+	maxFeeStr := ctx.String(maxFeePerGasFlag.Name)
+	maxTipStr := ctx.String(maxPriorityFeePerGasFlag.Name)
+	if gasPriceStr != "" && (maxFeeStr != "" || maxTipStr != "") {
+		return errors.New("can not specify both gasPrice and maxFeePerGas")
+	}
+	txArgs.gasPrice, err = parseBigIntArgument("gas price", gasPriceStr)
+	if err != nil {
+		return err
+	}
+	txArgs.maxFeePerGas, err = parseBigIntArgument("max fee per gas", maxFeeStr)
+	if err != nil {
+		return err
+	}
+	txArgs.maxPriorityFeePerGas, err = parseBigIntArgument("max priority fee per gas", maxTipStr)
+	if err != nil {
+		return err
+	}
+	if txArgs.maxFeePerGas != nil && txArgs.maxPriorityFeePerGas != nil &&
+		txArgs.maxPriorityFeePerGas.Cmp(txArgs.maxFeePerGas) > 0 {
+		return fmt.Errorf("max priority fee per gas %v is greater than max fee per gas %v", txArgs.maxPriorityFeePerGas, txArgs.maxFeePerGas)
+	}
+	return checkGasOptionArguments(ctx)
+}
+
+// parseBigIntArgument returns nil if str is empty
+func parseBigIntArgument(name, str string) (*big.Int, error) {
+	if str == "" {
+		return nil, nil
+	}
+	bi, ok := new(big.Int).SetString(str, 0)
+	if !ok || bi.Sign() < 0 {
+		return nil, fmt.Errorf("wrong %v %v", name, str)
+	}
+	return bi, nil
+}
+
+func checkAccessListArgument(ctx *cli.Context) error {
+	txArgs.accessList = nil
+	accessListFile := ctx.String(accessListFlag.Name)
+	if accessListFile == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(accessListFile)
+	if err != nil {
+		return fmt.Errorf("read access list file failed, %w", err)
+	}
+	var accessList types.AccessList
+	err = json.Unmarshal(data, &accessList)
+	if err != nil {
+		return fmt.Errorf("wrong access list file %v, %w", accessListFile, err)
+	}
+	txArgs.accessList = accessList
+	log.Info("load access list success", "addresses", len(accessList), "storageKeys", accessList.StorageKeys())
+	return nil
+}
+
+// buildRawTx build EIP-1559 dynamic fee tx if max fee per gas is specified,
+// or EIP-2930 access list tx if access list is specified,
+// otherwise build legacy tx.
+func buildRawTx(nonce uint64) *types.Transaction {
+	var to *common.Address
+	if !txArgs.createContract {
+		to = &txArgs.to
+	}
+	if txArgs.maxFeePerGas != nil {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    txArgs.chainID,
+			Nonce:      nonce,
+			GasTipCap:  txArgs.maxPriorityFeePerGas,
+			GasFeeCap:  txArgs.maxFeePerGas,
+			Gas:        txArgs.gasLimit,
+			To:         to,
+			Value:      txArgs.value,
+			Data:       txArgs.input,
+			AccessList: txArgs.accessList,
+		})
+	}
+	if txArgs.accessList != nil {
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    txArgs.chainID,
+			Nonce:      nonce,
+			GasPrice:   txArgs.gasPrice,
+			Gas:        txArgs.gasLimit,
+			To:         to,
+			Value:      txArgs.value,
+			Data:       txArgs.input,
+			AccessList: txArgs.accessList,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: txArgs.gasPrice,
+		Gas:      txArgs.gasLimit,
+		To:       to,
+		Value:    txArgs.value,
+		Data:     txArgs.input,
+	})
+}
+
 func sendEthTx(ctx *cli.Context) (err error) {
 	utils.SetLogger(ctx)
 	mpcCfg.NeedKeyStore = true
@@ -151,6 +269,10 @@ func sendEthTx(ctx *cli.Context) (err error) {
 	if err != nil {
 		return err
 	}
+	err = checkECDSAKeyType()
+	if err != nil {
+		return err
+	}
 	err = checkSendEthTxArguments(ctx)
 	if err != nil {
 		return err
@@ -173,75 +295,100 @@ func sendEthTx(ctx *cli.Context) (err error) {
 		log.Info("get account nonce success", "account", txArgs.from.String(), "nonce", nonce)
 	}
 
-	var rawTx *types.Transaction
-	if txArgs.createContract {
-		rawTx = types.NewContractCreation(nonce, txArgs.value, txArgs.gasLimit, txArgs.gasPrice, txArgs.input)
-	} else {
-		rawTx = types.NewTransaction(nonce, txArgs.to, txArgs.value, txArgs.gasLimit, txArgs.gasPrice, txArgs.input)
+	err = fillGasArguments()
+	if err != nil {
+		return err
 	}
-	log.Info("create raw tx success")
+
+	rawTx := buildRawTx(nonce)
+	log.Info("create raw tx success", "type", rawTx.Type())
 	_ = printTx(rawTx, true)
 
-	chainSigner := types.NewEIP155Signer(txArgs.chainID)
+	return mpcSignAndSendTx(rawTx, "ethtx")
+}
+
+// mpcSignAndSendTx mpc sign tx, then send the signed tx if it's not dry run.
+func mpcSignAndSendTx(rawTx *types.Transaction, contextType string, extraContexts ...string) (err error) {
+	signedTx, err := mpcSignTx(rawTx, contextType, extraContexts...)
+	if err != nil {
+		return err
+	}
+	if txArgs.dryrun {
+		return nil
+	}
+	return sendAndWaitTx(signedTx, txArgs.from)
+}
+
+// mpcSignTx mpc sign tx with message context
+// [contextType, txJSON, chainID, extraContexts..., memo(optional)],
+// and check the sender of the signed tx is the from address.
+func mpcSignTx(rawTx *types.Transaction, contextType string, extraContexts ...string) (*types.Transaction, error) {
+	chainSigner := types.LatestSignerForChainID(txArgs.chainID)
 	msgHash := chainSigner.Hash(rawTx)
 	txJSON, err := json.Marshal(rawTx)
 	if err != nil {
 		log.Error("json marshal tx failed")
-		return err
+		return nil, err
 	}
-	msgContext := []string{"ethtx", string(txJSON), txArgs.chainID.String()}
+	msgContext := []string{contextType, string(txJSON), txArgs.chainID.String()}
+	msgContext = append(msgContext, extraContexts...)
 	if signMemoArg != "" {
 		msgContext = append(msgContext, signMemoArg)
 	}
 
-	keyID, rsvs, err := mpcrpc.DoSign(mpcPublicKey, []string{msgHash.String()}, msgContext)
+	keyID, rsvs, err := mpcClient.DoSignContext(bgCtx, mpcPublicKey, []string{msgHash.String()}, msgContext)
 	if err != nil {
 		log.Error("mpc sign failed", "err", err)
-		return err
+		return nil, err
 	}
 	log.Info("mpc sign success", "keyID", keyID)
 
 	if len(rsvs) != 1 {
 		log.Error("mpc sign result rsv count is wrong", "have", len(rsvs), "want", 1)
-		return errors.New("mpc sign result rsv count is wrong")
+		return nil, errors.New("mpc sign result rsv count is wrong")
 	}
 	rsv := rsvs[0]
 
 	signature := common.FromHex(rsv)
 	if len(signature) != crypto.SignatureLength {
 		log.Error("mpc sign result rsv length is wrong", "rsv", rsv)
-		return errors.New("mpc sign result rsv length is wrong")
+		return nil, errors.New("mpc sign result rsv length is wrong")
 	}
 
 	signedTx, err := rawTx.WithSignature(chainSigner, signature)
 	if err != nil {
 		log.Error("sign tx failed", "err", err)
-		return err
+		return nil, err
 	}
 
 	sender, err := types.Sender(chainSigner, signedTx)
 	if err != nil {
 		log.Error("get sender from signed tx failed", "err", err)
-		return err
+		return nil, err
 	}
 
 	if sender != txArgs.from {
 		log.Error("sender mismatch", "signer", sender.String(), "sender", txArgs.from.String())
-		return errors.New("sender mismatch")
+		return nil, errors.New("sender mismatch")
 	}
 
-	txHash := signedTx.Hash().String()
-
-	log.Info("mpc sign tx success", "txHash", txHash, "sender", sender.String())
+	log.Info("mpc sign tx success", "txHash", signedTx.Hash().String(), "sender", sender.String())
 	_ = printTx(signedTx, false)
+	return signedTx, nil
+}
 
-	if !txArgs.dryrun {
-		err = sendSignedTransaction(signedTx)
-		if err != nil {
-			log.Error("send tx failed", "err", err)
-			return err
-		}
-		log.Info("send tx success", "txHash", txHash)
+// sendAndWaitTx send signed tx to gateways, and wait its receipt if specified.
+func sendAndWaitTx(signedTx *types.Transaction, from common.Address) error {
+	txHash := signedTx.Hash().String()
+	err := sendSignedTransaction(signedTx)
+	if err != nil {
+		log.Error("send tx failed", "err", err)
+		return err
+	}
+	log.Info("send tx success", "txHash", txHash)
+
+	if waitArgs.wait {
+		return waitTxReceipt(signedTx, from)
 	}
 	return nil
 }
@@ -310,11 +457,17 @@ func printTx(tx *types.Transaction, jsonFmt bool) error {
 		}
 		fmt.Println(string(bs))
 		_, r, _ := tx.RawSignatureValues()
-		if r == nil || r.Sign() == 0 {
+		switch {
+		case tx.Type() == types.AccessListTxType:
+			fmt.Printf("tx type is %v, chainID is %v, value is %v, nonce is %v, gasPrice is %v, gasLimit is %v\n", tx.Type(), tx.ChainId(), tx.Value(), tx.Nonce(), tx.GasPrice(), tx.Gas())
+		case tx.Type() == types.DynamicFeeTxType:
+			fmt.Printf("tx type is %v, chainID is %v, value is %v, nonce is %v, maxFeePerGas is %v, maxPriorityFeePerGas is %v, gasLimit is %v\n", tx.Type(), tx.ChainId(), tx.Value(), tx.Nonce(), tx.GasFeeCap(), tx.GasTipCap(), tx.Gas())
+		case r == nil || r.Sign() == 0:
 			fmt.Printf("tx value is %v, nonce is %v, gasPrice is %v, gasLimit is %v\n", tx.Value(), tx.Nonce(), tx.GasPrice(), tx.Gas())
-		} else {
+		default:
 			fmt.Printf("tx chainID is %v, value is %v, nonce is %v, gasPrice is %v, gasLimit is %v\n", tx.ChainId(), tx.Value(), tx.Nonce(), tx.GasPrice(), tx.Gas())
 		}
+		printAccessList(tx.AccessList())
 	} else {
 		bs, err := tx.MarshalBinary()
 		if err != nil {
@@ -324,3 +477,16 @@ func printTx(tx *types.Transaction, jsonFmt bool) error {
 	}
 	return nil
 }
+
+func printAccessList(accessList types.AccessList) {
+	if len(accessList) == 0 {
+		return
+	}
+	fmt.Printf("tx access list has %v addresses and %v storage keys\n", len(accessList), accessList.StorageKeys())
+	for _, tuple := range accessList {
+		fmt.Printf("  address %v\n", tuple.Address.String())
+		for _, key := range tuple.StorageKeys {
+			fmt.Printf("    storage key %v\n", key.String())
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	signBatchCommand = &cli.Command{
+		Action:    signBatch,
+		Name:      "signbatch",
+		Usage:     "sign multiple message hashes in batch",
+		ArgsUsage: "",
+		Description: `
+every entry of batch file has a message hash and its message context,
+eg. {"msgHash": "0x...", "msgContext": ["plaintext", "hello"]},
+or message hashes of the context signing multiple hashes (eg. btctx),
+eg. {"msgHashes": ["0x...", "0x..."], "msgContext": ["btctx", "{...}"]}.
+the entries are signed by mpc sign requests of at most 'maxBatchSize' hashes,
+the hashes of an entry are always signed in the same request.`,
+		Flags: []cli.Flag{
+			pubkeyFlag,
+			batchFileFlag,
+			maxBatchSizeFlag,
+			outputFlag,
+			gidFlag,
+			thresholdFlag,
+			signModeFlag,
+			signMemoFlag,
+			mpcServerFlag,
+			mpcKeystoreFlag,
+			mpcPasswordFlag,
+			signTypeFlag,
+			apiPrefixFlag,
+			rpcTimeoutFlag,
+			signTimeoutFlag,
+		},
+	}
+)
+
+// batchSignEntry is also the entry of batch message context,
+// msgHash is normalized to msgHashes when loading batch file.
+type batchSignEntry struct {
+	MsgHash    string   `json:"msgHash,omitempty"`
+	MsgHashes  []string `json:"msgHashes,omitempty"`
+	MsgContext []string `json:"msgContext"`
+}
+
+type batchSignResult struct {
+	MsgHash string `json:"msgHash"`
+	KeyID   string `json:"keyID"`
+	Rsv     string `json:"rsv"`
+}
+
+func signBatch(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	mpcCfg.NeedKeyStore = true
+	err = checkAndInitMpcConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	maxBatchSize := int(ctx.Uint64(maxBatchSizeFlag.Name))
+	if maxBatchSize == 0 {
+		return errors.New("max batch size must be positive")
+	}
+	batchFile := ctx.String(batchFileFlag.Name)
+	if batchFile == "" {
+		return errors.New("must specify batch file")
+	}
+	entries, err := loadBatchSignEntries(batchFile, mpcClient.SignType(), maxBatchSize)
+	if err != nil {
+		return err
+	}
+	log.Info("load batch sign entries success", "entries", len(entries), "maxBatchSize", maxBatchSize)
+
+	var results []*batchSignResult
+	for _, chunk := range chunkBatchSignEntries(entries, maxBatchSize) {
+		chunkResults, errf := signBatchEntries(chunk)
+		if errf != nil {
+			log.Error("mpc batch sign failed", "entries", len(chunk), "err", errf)
+			return errf
+		}
+		results = append(results, chunkResults...)
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(ctx.String(outputFlag.Name), data)
+}
+
+func loadBatchSignEntries(batchFile, signType string, maxBatchSize int) ([]*batchSignEntry, error) {
+	data, err := ioutil.ReadFile(batchFile)
+	if err != nil {
+		return nil, fmt.Errorf("read batch file failed, %w", err)
+	}
+	var entries []*batchSignEntry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("wrong batch file %v, %w", batchFile, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("wrong batch file %v, no entries", batchFile)
+	}
+	exist := make(map[string]struct{}, len(entries))
+	for i, entry := range entries {
+		if entry.MsgHash != "" {
+			if len(entry.MsgHashes) > 0 {
+				return nil, fmt.Errorf("can not specify both msgHash and msgHashes of entry %v", i+1)
+			}
+			entry.MsgHashes = []string{entry.MsgHash}
+			entry.MsgHash = ""
+		}
+		switch {
+		case len(entry.MsgHashes) == 0:
+			return nil, fmt.Errorf("empty message hash of entry %v", i+1)
+		case len(entry.MsgHashes) > maxBatchSize:
+			return nil, fmt.Errorf("message hash count %v of entry %v exceeds max batch size %v", len(entry.MsgHashes), i+1, maxBatchSize)
+		}
+		for _, msgHash := range entry.MsgHashes {
+			hash, errf := hexutil.Decode(msgHash)
+			if errf != nil || (signType == mpcrpc.KeyTypeECDSA && len(hash) != common.HashLength) {
+				return nil, fmt.Errorf("wrong message hash '%v' of entry %v", msgHash, i+1)
+			}
+			key := strings.ToLower(msgHash)
+			if _, dup := exist[key]; dup {
+				return nil, fmt.Errorf("duplicate message hash '%v' of entry %v", msgHash, i+1)
+			}
+			exist[key] = struct{}{}
+		}
+		if len(entry.MsgContext) == 0 {
+			return nil, fmt.Errorf("empty message context of entry %v", i+1)
+		}
+		if strings.EqualFold(entry.MsgContext[0], "batch") {
+			return nil, fmt.Errorf("nested batch message context of entry %v", i+1)
+		}
+	}
+	return entries, nil
+}
+
+// chunkBatchSignEntries splits entries to chunks of at most maxBatchSize hashes
+func chunkBatchSignEntries(entries []*batchSignEntry, maxBatchSize int) (chunks [][]*batchSignEntry) {
+	var chunk []*batchSignEntry
+	var hashes int
+	for _, entry := range entries {
+		if hashes+len(entry.MsgHashes) > maxBatchSize {
+			chunks = append(chunks, chunk)
+			chunk, hashes = nil, 0
+		}
+		chunk = append(chunk, entry)
+		hashes += len(entry.MsgHashes)
+	}
+	return append(chunks, chunk)
+}
+
+// parseBatchEntries parse the entries of batch message context
+// ["batch", json array of entries, memo(optional)] to sign infos,
+// the message hashes of entries in order must be the hashes of batch.
+func parseBatchEntries(signInfo *mpcrpc.SignInfoData) ([]*mpcrpc.SignInfoData, error) {
+	msgContexts := signInfo.MsgContext
+	if len(msgContexts) < 2 {
+		return nil, errors.New("wrong message context length, must have at least two elements")
+	}
+	var entries []*batchSignEntry
+	if err := json.Unmarshal([]byte(msgContexts[1]), &entries); err != nil {
+		return nil, fmt.Errorf("json unmarshal batch message contexts failed. %w", err)
+	}
+	infos := make([]*mpcrpc.SignInfoData, len(entries))
+	var count int
+	for i, entry := range entries {
+		if len(entry.MsgContext) > 0 && strings.EqualFold(entry.MsgContext[0], "batch") {
+			return nil, errors.New("nested batch sign is not allowed")
+		}
+		if len(entry.MsgHashes) == 0 {
+			return nil, fmt.Errorf("empty message hash of batch entry %v", i+1)
+		}
+		for _, msgHash := range entry.MsgHashes {
+			if count >= len(signInfo.MsgHash) || !strings.EqualFold(signInfo.MsgHash[count], msgHash) {
+				return nil, fmt.Errorf("message hash %v of batch entry %v mismatch with message hashes of sign", msgHash, i+1)
+			}
+			count++
+		}
+		info := *signInfo
+		info.MsgHash = entry.MsgHashes
+		info.MsgContext = entry.MsgContext
+		infos[i] = &info
+	}
+	if count != len(signInfo.MsgHash) {
+		return nil, fmt.Errorf("batch message hash count %v mismatch with message hash count %v", count, len(signInfo.MsgHash))
+	}
+	return infos, nil
+}
+
+// signBatchEntries sign entries in one mpc sign request with message context
+// ["batch", json array of entries, memo(optional)], the acceptors
+// verify every entry individually.
+func signBatchEntries(entries []*batchSignEntry) ([]*batchSignResult, error) {
+	var msgHashes []string
+	for _, entry := range entries {
+		msgHashes = append(msgHashes, entry.MsgHashes...)
+	}
+	contextsJSON, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	msgContext := []string{"batch", string(contextsJSON)}
+	if signMemoArg != "" {
+		msgContext = append(msgContext, signMemoArg)
+	}
+
+	keyID, rsvs, err := mpcClient.DoSignContext(bgCtx, mpcPublicKey, msgHashes, msgContext)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("mpc batch sign success", "keyID", keyID, "msgHashes", len(msgHashes))
+
+	if len(rsvs) != len(msgHashes) {
+		log.Error("mpc sign result rsv count is wrong", "have", len(rsvs), "want", len(msgHashes))
+		return nil, errors.New("mpc sign result rsv count is wrong")
+	}
+	signType := mpcClient.SignType()
+	// assign every rsv to the hash it signs regardless of the order of sign result
+	rsvs, err = mpcrpc.MatchSignatures(signType, mpcPublicKey, msgHashes, rsvs)
+	if err != nil {
+		log.Error("match mpc sign result to message hashes failed", "keyID", keyID, "err", err)
+		return nil, err
+	}
+	results := make([]*batchSignResult, len(rsvs))
+	for i, rsv := range rsvs {
+		if len(common.FromHex(rsv)) != mpcrpc.SignatureLength(signType) {
+			log.Error("mpc sign result rsv length is wrong", "rsv", rsv, "keytype", signType)
+			return nil, errors.New("mpc sign result rsv length is wrong")
+		}
+		results[i] = &batchSignResult{
+			MsgHash: msgHashes[i],
+			KeyID:   keyID,
+			Rsv:     rsv,
+		}
+	}
+	return results, nil
+}
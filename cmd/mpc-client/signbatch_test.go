@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/anyswap/mpc-client/mpcrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadBatchSignEntries(t *testing.T) {
+	batchFile := filepath.Join(t.TempDir(), "batch.json")
+	writeBatch := func(content string) {
+		assert.Nil(t, ioutil.WriteFile(batchFile, []byte(content), 0600))
+	}
+	hash := func(b string) string {
+		return "0x" + b + "000000000000000000000000000000000000000000000000000000000000000"
+	}
+
+	writeBatch(`[
+		{"msgHash": "` + hash("1") + `", "msgContext": ["plaintext", "a"]},
+		{"msgHashes": ["` + hash("2") + `", "` + hash("3") + `"], "msgContext": ["btctx", "{}"]},
+		{"msgHash": "` + hash("4") + `", "msgContext": ["plaintext", "b"]}
+	]`)
+	entries, err := loadBatchSignEntries(batchFile, mpcrpc.KeyTypeECDSA, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{hash("1")}, entries[0].MsgHashes)
+	assert.Equal(t, "", entries[0].MsgHash)
+
+	// the hashes of an entry are signed in the same request
+	chunks := chunkBatchSignEntries(entries, 2)
+	assert.Equal(t, 3, len(chunks))
+	assert.Equal(t, 2, len(chunks[1][0].MsgHashes))
+	assert.Equal(t, 2, len(chunkBatchSignEntries(entries, 3)))
+
+	// entry hashes exceed max batch size
+	_, err = loadBatchSignEntries(batchFile, mpcrpc.KeyTypeECDSA, 1)
+	assert.NotNil(t, err)
+
+	writeBatch(`[{"msgHash": "` + hash("1") + `", "msgHashes": ["` + hash("2") + `"], "msgContext": ["plaintext", "a"]}]`)
+	_, err = loadBatchSignEntries(batchFile, mpcrpc.KeyTypeECDSA, 2)
+	assert.NotNil(t, err)
+
+	writeBatch(`[{"msgHashes": ["` + hash("1") + `", "` + hash("1") + `"], "msgContext": ["btctx", "{}"]}]`)
+	_, err = loadBatchSignEntries(batchFile, mpcrpc.KeyTypeECDSA, 2)
+	assert.NotNil(t, err)
+}
@@ -67,7 +67,7 @@ func signPlainText(ctx *cli.Context) (err error) {
 		msgContext = append(msgContext, signMemoArg)
 	}
 
-	keyID, rsvs, err := mpcrpc.DoSign(mpcPublicKey, []string{signContent}, msgContext)
+	keyID, rsvs, err := mpcClient.DoSignContext(bgCtx, mpcPublicKey, []string{signContent}, msgContext)
 	if err != nil {
 		log.Error("mpc sign failed", "err", err)
 		return err
@@ -80,9 +80,10 @@ func signPlainText(ctx *cli.Context) (err error) {
 	}
 	rsv := rsvs[0]
 
+	signType := mpcClient.SignType()
 	signature := common.FromHex(rsv)
-	if len(signature) != crypto.SignatureLength {
-		log.Error("mpc sign result rsv length is wrong", "rsv", rsv)
+	if len(signature) != mpcrpc.SignatureLength(signType) {
+		log.Error("mpc sign result rsv length is wrong", "rsv", rsv, "keytype", signType)
 		return errors.New("mpc sign result rsv length is wrong")
 	}
 
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/anyswap/mpc-client/cmd/utils"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	signTypedDataCommand = &cli.Command{
+		Action:      signTypedData,
+		Name:        "signtypeddata",
+		Usage:       "sign eip712 typed data",
+		ArgsUsage:   "",
+		Description: ``,
+		Flags: []cli.Flag{
+			pubkeyFlag,
+			typedDataFlag,
+			gidFlag,
+			thresholdFlag,
+			signModeFlag,
+			signMemoFlag,
+			mpcServerFlag,
+			mpcKeystoreFlag,
+			mpcPasswordFlag,
+			signTypeFlag,
+			apiPrefixFlag,
+			rpcTimeoutFlag,
+			signTimeoutFlag,
+		},
+	}
+)
+
+func signTypedData(ctx *cli.Context) (err error) {
+	utils.SetLogger(ctx)
+	mpcCfg.NeedKeyStore = true
+	err = checkAndInitMpcConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+	err = checkECDSAKeyType()
+	if err != nil {
+		return err
+	}
+
+	typedDataFile := ctx.String(typedDataFlag.Name)
+	if typedDataFile == "" {
+		return errors.New("must specify typed data file")
+	}
+	data, err := ioutil.ReadFile(typedDataFile)
+	if err != nil {
+		return fmt.Errorf("read typed data file failed, %w", err)
+	}
+	td, err := parseTypedData(data)
+	if err != nil {
+		return err
+	}
+	msgHash, err := td.hash()
+	if err != nil {
+		return err
+	}
+	log.Info("hash typed data success", "primaryType", td.PrimaryType, "msgHash", msgHash.String())
+	fmt.Print(td.format())
+
+	// keep the original document to let the acceptors hash it by themselves
+	var compacted bytes.Buffer
+	err = json.Compact(&compacted, data)
+	if err != nil {
+		return err
+	}
+	msgContext := []string{"eip712", compacted.String()}
+	if signMemoArg != "" {
+		msgContext = append(msgContext, signMemoArg)
+	}
+
+	keyID, rsvs, err := mpcClient.DoSignContext(bgCtx, mpcPublicKey, []string{msgHash.String()}, msgContext)
+	if err != nil {
+		log.Error("mpc sign failed", "err", err)
+		return err
+	}
+	log.Info("mpc sign success", "keyID", keyID)
+
+	if len(rsvs) != 1 {
+		log.Error("mpc sign result rsv count is wrong", "have", len(rsvs), "want", 1)
+		return errors.New("mpc sign result rsv count is wrong")
+	}
+	rsv := rsvs[0]
+
+	signature := common.FromHex(rsv)
+	if len(signature) != crypto.SignatureLength {
+		log.Error("mpc sign result rsv length is wrong", "rsv", rsv)
+		return errors.New("mpc sign result rsv length is wrong")
+	}
+	pubkey, err := crypto.SigToPub(msgHash.Bytes(), signature)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("signer is", crypto.PubkeyToAddress(*pubkey).String())
+	fmt.Println("rsv is", rsv)
+	return nil
+}
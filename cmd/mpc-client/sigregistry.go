@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+const builtinSignatureSource = "builtin"
+
+// sigRegistry method selectors and event topics of builtin and loaded signatures
+var sigRegistry = newSignatureRegistry()
+
+// registryEntry a method or event signature and the file it's loaded from,
+// the method of abi file has named arguments, otherwise is parsed from signature.
+type registryEntry struct {
+	Signature string
+	Source    string
+
+	method *abi.Method
+}
+
+// signatureRegistry different signatures of the same selector or topic are
+// all kept as candidates in the order of loading.
+type signatureRegistry struct {
+	methods map[string][]*registryEntry // key is hex selector
+	events  map[string][]*registryEntry // key is hex topic
+}
+
+func newSignatureRegistry() *signatureRegistry {
+	r := &signatureRegistry{
+		methods: make(map[string][]*registryEntry),
+		events:  make(map[string][]*registryEntry),
+	}
+	selectors := make([]string, 0, len(knownContractMethods))
+	for selector := range knownContractMethods {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+	for _, selector := range selectors {
+		r.addMethod(knownContractMethods[selector], builtinSignatureSource, nil)
+	}
+	return r
+}
+
+// loadSignatureRegistry loads the abi files, signature directory and token decimals of command flags
+func loadSignatureRegistry(ctx *cli.Context) error {
+	if err := loadTokenDecimals(ctx); err != nil {
+		return err
+	}
+	for _, file := range ctx.StringSlice(abiFilesFlag.Name) {
+		if err := sigRegistry.loadABIFile(file); err != nil {
+			return err
+		}
+	}
+	if dir := ctx.String(signatureDirFlag.Name); dir != "" {
+		return sigRegistry.loadDir(dir)
+	}
+	return nil
+}
+
+func (r *signatureRegistry) addMethod(sig, source string, method *abi.Method) {
+	sig = strings.ReplaceAll(sig, " ", "")
+	selector := hex.EncodeToString(crypto.Keccak256([]byte(sig))[:4])
+	r.methods[selector] = r.addEntry(r.methods[selector], "method", sig, source, method)
+}
+
+func (r *signatureRegistry) addEvent(sig, source string) {
+	sig = strings.ReplaceAll(sig, " ", "")
+	topic := hex.EncodeToString(crypto.Keccak256([]byte(sig)))
+	r.events[topic] = r.addEntry(r.events[topic], "event", sig, source, nil)
+}
+
+func (r *signatureRegistry) addEntry(entries []*registryEntry, kind, sig, source string, method *abi.Method) []*registryEntry {
+	for _, entry := range entries {
+		if entry.Signature == sig {
+			// prefer the method with named arguments
+			if entry.method == nil && method != nil {
+				entry.method = method
+				entry.Source = source
+			}
+			return entries
+		}
+	}
+	if len(entries) > 0 {
+		log.Warn("signature collision detected", "kind", kind, "signature", sig, "source", source,
+			"exist", entries[0].Signature, "existSource", entries[0].Source)
+	}
+	return append(entries, &registryEntry{Signature: sig, Source: source, method: method})
+}
+
+// loadDir loads abi json files (*.json) and signature list files (others)
+// in the directory, sub directories and hidden files are skipped.
+func (r *signatureRegistry) loadDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read signature directory failed, %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			err = r.loadABIFile(path)
+		} else {
+			err = r.loadSignatureFile(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadABIFile loads methods and events of abi json file,
+// or of the 'abi' field of compiled artifact file.
+func (r *signatureRegistry) loadABIFile(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read abi file failed, %w", err)
+	}
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err = json.Unmarshal(data, &artifact); err == nil && len(artifact.ABI) > 0 {
+			data = artifact.ABI
+		}
+	}
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("wrong abi file %v, %w", file, err)
+	}
+	for _, method := range parsed.Methods {
+		method := method
+		r.addMethod(method.Sig, file, &method)
+	}
+	for _, event := range parsed.Events {
+		r.addEvent(event.Sig, file)
+	}
+	log.Info("load abi file success", "file", file, "methods", len(parsed.Methods), "events", len(parsed.Events))
+	return nil
+}
+
+// loadSignatureFile loads signature list file, every line is a method signature
+// or an event signature with 'event' prefix, '#' starts comment.
+// eg. `transfer(address,uint256)`, `event Transfer(address,address,uint256)`
+func (r *signatureRegistry) loadSignatureFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("open signature file failed, %w", err)
+	}
+	defer f.Close()
+
+	var methods, events int
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if idx := strings.Index(text, "#"); idx >= 0 {
+			text = text[:idx]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		isEvent := strings.HasPrefix(text, "event ")
+		if isEvent {
+			text = strings.TrimSpace(strings.TrimPrefix(text, "event "))
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "function "))
+		if _, err = parseMethodSignature(text); err != nil {
+			return fmt.Errorf("wrong signature file %v at line %v, %w", file, line, err)
+		}
+		if isEvent {
+			r.addEvent(text, file)
+			events++
+		} else {
+			r.addMethod(text, file, nil)
+			methods++
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("read signature file failed, %w", err)
+	}
+	log.Info("load signature file success", "file", file, "methods", methods, "events", events)
+	return nil
+}
+
+func (r *signatureRegistry) lookupMethod(selector []byte) []*registryEntry {
+	return r.methods[hex.EncodeToString(selector)]
+}
+
+func (r *signatureRegistry) lookupEvent(topic common.Hash) []*registryEntry {
+	return r.events[hex.EncodeToString(topic.Bytes())]
+}
+
+// abiMethod returns the method of abi file, or parses it from signature
+func (e *registryEntry) abiMethod() (*abi.Method, error) {
+	if e.method != nil {
+		return e.method, nil
+	}
+	method, err := parseMethodSignature(e.Signature)
+	if err != nil {
+		return nil, err
+	}
+	e.method = method
+	return method, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureRegistry(t *testing.T) {
+	dir := t.TempDir()
+	sigList := `
+# known collision of transferFrom(address,address,uint256)
+gasprice_bit_ether(int128)
+event Transfer(address,address,uint256)
+`
+	abiJSON := `{"abi": [{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]}]}`
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "sigs.txt"), []byte(sigList), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "erc20.json"), []byte(abiJSON), 0600))
+
+	r := newSignatureRegistry()
+	assert.Nil(t, r.loadDir(dir))
+
+	entries := r.lookupMethod(common.FromHex("0x23b872dd"))
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "transferFrom(address,address,uint256)", entries[0].Signature)
+	assert.Equal(t, "gasprice_bit_ether(int128)", entries[1].Signature)
+
+	// method of abi file with named arguments takes place of builtin
+	entries = r.lookupMethod(common.FromHex("0xa9059cbb"))
+	assert.Equal(t, 1, len(entries))
+	method, err := entries[0].abiMethod()
+	assert.Nil(t, err)
+	assert.Equal(t, "to", method.Inputs[0].Name)
+
+	topic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	entries = r.lookupEvent(topic)
+	assert.Equal(t, 1, len(entries))
+}
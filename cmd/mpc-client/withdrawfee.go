@@ -9,7 +9,6 @@ import (
 
 	"github.com/anyswap/mpc-client/cmd/utils"
 	"github.com/anyswap/mpc-client/log"
-	"github.com/anyswap/mpc-client/mpcrpc"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -45,8 +44,18 @@ var (
 			valueFlag,
 			gasLimitFlag,
 			gasPriceFlag,
+			maxFeePerGasFlag,
+			maxPriorityFeePerGasFlag,
 			inputFlag,
+			accessListFlag,
+			gasLimitMultiplierFlag,
+			gasPriceMultiplierFlag,
+			maxGasFeeFlag,
+			maxTotalFeeFlag,
 			dryrunFlag,
+			waitFlag,
+			confirmationsFlag,
+			waitTimeoutFlag,
 		},
 	}
 )
@@ -55,6 +64,7 @@ func checkWithdrawFeeArguments(ctx *cli.Context) (err error) {
 	txArgs.gateways = ctx.StringSlice(gatewaysFlag.Name)
 	txArgs.gasLimit = ctx.Uint64(gasLimitFlag.Name)
 	txArgs.dryrun = ctx.Bool(dryrunFlag.Name)
+	checkWaitReceiptArguments(ctx)
 
 	fromAddrStr := ctx.String(fromAddrFlag.Name)
 	if !common.IsHexAddress(fromAddrStr) {
@@ -68,13 +78,13 @@ func checkWithdrawFeeArguments(ctx *cli.Context) (err error) {
 	}
 	txArgs.to = common.HexToAddress(toAddrStr)
 
-	var ok bool
-	gasPriceStr := ctx.String(gasPriceFlag.Name)
-	txArgs.gasPrice, ok = new(big.Int).SetString(gasPriceStr, 0)
-	if !ok {
-		return fmt.Errorf("wrong gas price %v", gasPriceStr)
+	err = checkGasPriceArguments(ctx)
+	if err != nil {
+		return err
 	}
 
+	var ok bool
+
 	nodeChainIDStr := ctx.String(chainIDFlag.Name)
 	txArgs.chainID, ok = new(big.Int).SetString(nodeChainIDStr, 0)
 	if !ok {
@@ -105,6 +115,11 @@ func checkWithdrawFeeArguments(ctx *cli.Context) (err error) {
 		}
 	}
 
+	err = checkAccessListArgument(ctx)
+	if err != nil {
+		return err
+	}
+
 	log.Info("check arguments pass")
 	return nil
 }
@@ -116,6 +131,10 @@ func withdrawFee(ctx *cli.Context) (err error) {
 	if err != nil {
 		return err
 	}
+	err = checkECDSAKeyType()
+	if err != nil {
+		return err
+	}
 	err = checkWithdrawFeeArguments(ctx)
 	if err != nil {
 		return err
@@ -138,16 +157,16 @@ func withdrawFee(ctx *cli.Context) (err error) {
 		log.Info("get account nonce success", "account", txArgs.from.String(), "nonce", nonce)
 	}
 
-	var rawTx *types.Transaction
-	if txArgs.createContract {
-		rawTx = types.NewContractCreation(nonce, txArgs.value, txArgs.gasLimit, txArgs.gasPrice, txArgs.input)
-	} else {
-		rawTx = types.NewTransaction(nonce, txArgs.to, txArgs.value, txArgs.gasLimit, txArgs.gasPrice, txArgs.input)
+	err = fillGasArguments()
+	if err != nil {
+		return err
 	}
-	log.Info("create raw tx success")
+
+	rawTx := buildRawTx(nonce)
+	log.Info("create raw tx success", "type", rawTx.Type())
 	_ = printTx(rawTx, true)
 
-	chainSigner := types.NewEIP155Signer(txArgs.chainID)
+	chainSigner := types.LatestSignerForChainID(txArgs.chainID)
 	msgHash := chainSigner.Hash(rawTx)
 	txJSON, err := json.Marshal(rawTx)
 	if err != nil {
@@ -155,7 +174,7 @@ func withdrawFee(ctx *cli.Context) (err error) {
 		return err
 	}
 
-	senderSignature, err := mpcrpc.SignWithKey(msgHash[:])
+	senderSignature, err := mpcClient.SignWithKey(msgHash[:])
 	if err != nil {
 		return err
 	}
@@ -165,7 +184,7 @@ func withdrawFee(ctx *cli.Context) (err error) {
 		msgContext = append(msgContext, signMemoArg)
 	}
 
-	keyID, rsvs, err := mpcrpc.DoSign(mpcPublicKey, []string{msgHash.String()}, msgContext)
+	keyID, rsvs, err := mpcClient.DoSignContext(bgCtx, mpcPublicKey, []string{msgHash.String()}, msgContext)
 	if err != nil {
 		log.Error("mpc sign failed", "err", err)
 		return err
@@ -213,6 +232,10 @@ func withdrawFee(ctx *cli.Context) (err error) {
 			return err
 		}
 		log.Info("send tx success", "txHash", txHash)
+
+		if waitArgs.wait {
+			return waitTxReceipt(signedTx, txArgs.from)
+		}
 	}
 	return nil
 }
@@ -2,6 +2,7 @@
 package utils
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -74,6 +75,20 @@ func IsCleanuping() bool {
 	}
 }
 
+// CleanupContext returns a context which is canceled when cleanup begins
+// (eg. receiving interrupt signal) or when the returned cancel is called.
+func CleanupContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-CleanupChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
 // WaitAndCleanup wait and cleanup
 func WaitAndCleanup(doCleanup func()) {
 	<-CleanupChan
@@ -1,12 +1,13 @@
 package mpcrpc
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/anyswap/mpc-client/log"
 )
 
-func buildAcceptTx(txType, keyID, agreeResult string, msgHash, msgContext []string) (string, error) {
+func (c *Client) buildAcceptTx(txType, keyID, agreeResult string, msgHash, msgContext []string) (string, error) {
 	log.Info("buildAcceptTx", "txType", txType, "keyID", keyID, "agreeResult", agreeResult, "msgHash", msgHash, "msgContext", msgContext)
 	nonce := uint64(0)
 	data := AcceptData{
@@ -21,23 +22,33 @@ func buildAcceptTx(txType, keyID, agreeResult string, msgHash, msgContext []stri
 	if err != nil {
 		return "", err
 	}
-	return BuildMPCRawTx(nonce, payload)
+	return c.BuildMPCRawTx(nonce, payload)
 }
 
 // DoAcceptSign accept sign
-func DoAcceptSign(keyID, agreeResult string, msgHash, msgContext []string) (string, error) {
-	rawTX, err := buildAcceptTx("ACCEPTSIGN", keyID, agreeResult, msgHash, msgContext)
+func (c *Client) DoAcceptSign(keyID, agreeResult string, msgHash, msgContext []string) (string, error) {
+	return c.DoAcceptSignContext(context.Background(), keyID, agreeResult, msgHash, msgContext)
+}
+
+// DoAcceptSignContext accept sign with context
+func (c *Client) DoAcceptSignContext(ctx context.Context, keyID, agreeResult string, msgHash, msgContext []string) (string, error) {
+	rawTX, err := c.buildAcceptTx("ACCEPTSIGN", keyID, agreeResult, msgHash, msgContext)
 	if err != nil {
 		return "", err
 	}
-	return AcceptSign(rawTX)
+	return c.AcceptSignContext(ctx, rawTX)
 }
 
 // DoAcceptReqAddr accept request address
-func DoAcceptReqAddr(keyID, agreeResult string) (string, error) {
-	rawTX, err := buildAcceptTx("ACCEPTREQADDR", keyID, agreeResult, nil, nil)
+func (c *Client) DoAcceptReqAddr(keyID, agreeResult string) (string, error) {
+	return c.DoAcceptReqAddrContext(context.Background(), keyID, agreeResult)
+}
+
+// DoAcceptReqAddrContext accept request address with context
+func (c *Client) DoAcceptReqAddrContext(ctx context.Context, keyID, agreeResult string) (string, error) {
+	rawTX, err := c.buildAcceptTx("ACCEPTREQADDR", keyID, agreeResult, nil, nil)
 	if err != nil {
 		return "", err
 	}
-	return AcceptReqAddr(rawTX)
+	return c.AcceptReqAddrContext(ctx, rawTX)
 }
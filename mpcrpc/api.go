@@ -1,10 +1,12 @@
 package mpcrpc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/anyswap/mpc-client/log"
@@ -23,44 +25,93 @@ const (
 	successStatus = "Success"
 )
 
-func newWrongStatusError(subject, status, errInfo string) error {
-	return fmt.Errorf("[%v] Wrong status \"%v\", err=\"%v\"", subject, status, errInfo)
+func (c *Client) wrapPostError(method string, err error) error {
+	return fmt.Errorf("[post] %v error, %w", c.apiPrefix+method, err)
 }
 
-func wrapPostError(method string, err error) error {
-	return fmt.Errorf("[post] %v error, %w", mpcAPIPrefix+method, err)
+func (c *Client) httpPost(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	return c.httpPostTo(ctx, result, "", method, params...)
 }
 
-func httpPost(result interface{}, method string, params ...interface{}) error {
-	return client.RPCPostWithTimeout(mpcRPCTimeout, &result, mpcRPCAddress, mpcAPIPrefix+method, params...)
+func (c *Client) httpPostTo(ctx context.Context, result interface{}, rpcAddress, method string, params ...interface{}) error {
+	url := c.getRPCAddress(rpcAddress)
+	policy := c.getRPCRetryPolicy(ctx, method)
+	err := client.RPCPostWithRetry(ctx, policy, c.rpcTimeout, &result, url, c.apiPrefix+method, params...)
+	c.endpoints.track(ctx, url, err)
+	return err
 }
 
-func httpPostTo(result interface{}, rpcAddress, method string, params ...interface{}) error {
-	return client.RPCPostWithTimeout(mpcRPCTimeout, &result, rpcAddress, mpcAPIPrefix+method, params...)
+// httpPostFailover post to rpcAddress if it is not empty, otherwise
+// try the endpoints one by one until one of them answers.
+// It's used for read only methods which are safe to be resent.
+func (c *Client) httpPostFailover(ctx context.Context, result interface{}, rpcAddress, method string, params ...interface{}) error {
+	if rpcAddress != "" {
+		return c.httpPostTo(ctx, result, rpcAddress, method, params...)
+	}
+	err := errNoRPCEndpoint
+	for _, url := range c.endpoints.candidates() {
+		err = c.httpPostTo(ctx, result, url, method, params...)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		log.Warn("mpc rpc call failed, try next endpoint", "method", method, "url", url, "err", err)
+	}
+	return err
+}
+
+// httpBatchPostFailover post batch request of read only methods to
+// the endpoints one by one until one of them answers.
+func (c *Client) httpBatchPostFailover(ctx context.Context, batch []*client.BatchElem) error {
+	policy := c.getRPCRetryPolicy(ctx, "")
+	err := errNoRPCEndpoint
+	for _, url := range c.endpoints.candidates() {
+		err = policy.Do(ctx, func() error {
+			return client.BatchRPCPostWithContext(ctx, c.rpcTimeout, url, batch)
+		})
+		if err != nil && !client.IsRetryableError(err) {
+			return err // the endpoint answers, but may not support batch request
+		}
+		c.endpoints.track(ctx, url, err)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		log.Warn("mpc rpc batch call failed, try next endpoint", "url", url, "err", err)
+	}
+	return err
 }
 
 // GetEnode call getEnode
-func GetEnode(rpcAddr string) (string, error) {
+func (c *Client) GetEnode(rpcAddr string) (string, error) {
+	return c.GetEnodeContext(context.Background(), rpcAddr)
+}
+
+// GetEnodeContext call getEnode with context
+func (c *Client) GetEnodeContext(ctx context.Context, rpcAddr string) (string, error) {
 	var result GetEnodeResp
-	err := httpPostTo(&result, rpcAddr, "getEnode")
+	err := c.httpPostTo(ctx, &result, rpcAddr, "getEnode")
 	if err != nil {
-		return "", wrapPostError("getEnode", err)
+		return "", c.wrapPostError("getEnode", err)
 	}
 	if result.Status != successStatus {
-		return "", newWrongStatusError("getEnode", result.Status, result.Error)
+		return "", newStatusError("getEnode", result.Status, result.Tip, result.Error)
 	}
 	return result.Data.Enode, nil
 }
 
 // GetSignNonce call getSignNonce
-func GetSignNonce(mpcUser, rpcAddr string) (uint64, error) {
+func (c *Client) GetSignNonce(mpcUser, rpcAddr string) (uint64, error) {
+	return c.GetSignNonceContext(context.Background(), mpcUser, rpcAddr)
+}
+
+// GetSignNonceContext call getSignNonce with context
+func (c *Client) GetSignNonceContext(ctx context.Context, mpcUser, rpcAddr string) (uint64, error) {
 	var result DataResultResp
-	err := httpPostTo(&result, rpcAddr, "getSignNonce", mpcUser)
+	err := c.httpPostFailover(ctx, &result, rpcAddr, "getSignNonce", mpcUser)
 	if err != nil {
-		return 0, wrapPostError("getSignNonce", err)
+		return 0, c.wrapPostError("getSignNonce", err)
 	}
 	if result.Status != successStatus {
-		return 0, newWrongStatusError("getSignNonce", result.Status, result.Error)
+		return 0, newStatusError("getSignNonce", result.Status, result.Tip, result.Error)
 	}
 	bi, err := GetBigIntFromStr(result.Data.Result)
 	if err != nil {
@@ -70,59 +121,159 @@ func GetSignNonce(mpcUser, rpcAddr string) (uint64, error) {
 }
 
 // GetSignStatus call getSignStatus
-func GetSignStatus(key, rpcAddr string) (*SignStatus, error) {
+func (c *Client) GetSignStatus(key, rpcAddr string) (*SignStatus, error) {
+	return c.GetSignStatusContext(context.Background(), key, rpcAddr)
+}
+
+// GetSignStatusContext call getSignStatus with context
+func (c *Client) GetSignStatusContext(ctx context.Context, key, rpcAddr string) (*SignStatus, error) {
+	if rpcAddr == "" && c.endpoints != nil && c.endpoints.strategy == StrategyQuorum {
+		return c.getSignStatusByQuorum(ctx, key)
+	}
+	signStatus, err := c.querySignStatus(ctx, key, rpcAddr)
+	if err != nil {
+		return nil, err
+	}
+	return checkSignStatus(key, signStatus)
+}
+
+// getSignStatusByQuorum query sign status from all endpoints,
+// and accept the status agreed by the majority of them.
+func (c *Client) getSignStatusByQuorum(ctx context.Context, key string) (*SignStatus, error) {
+	type reply struct {
+		signStatus *SignStatus
+		err        error
+	}
+	urls := c.endpoints.urls()
+	replies := make(chan *reply, len(urls))
+	for _, url := range urls {
+		go func(url string) {
+			signStatus, err := c.querySignStatus(ctx, key, url)
+			replies <- &reply{signStatus: signStatus, err: err}
+		}(url)
+	}
+	quorum := c.endpoints.quorum()
+	votes := make(map[string]int, len(urls))
+	var lastErr error
+	for range urls {
+		r := <-replies
+		if r.err != nil {
+			lastErr = r.err
+			continue
+		}
+		vote := r.signStatus.Status + ":" + strings.Join(r.signStatus.Rsv, ",")
+		votes[vote]++
+		if votes[vote] >= quorum {
+			return checkSignStatus(key, r.signStatus)
+		}
+	}
+	log.Info("getSignStatus no quorum", "keyID", key, "quorum", quorum, "votes", votes, "lastErr", lastErr)
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w, %v", errNoQuorum, lastErr)
+	}
+	return nil, errNoQuorum
+}
+
+func (c *Client) querySignStatus(ctx context.Context, key, rpcAddr string) (*SignStatus, error) {
 	var result DataResultResp
-	err := httpPostTo(&result, rpcAddr, "getSignStatus", key)
+	err := c.httpPostFailover(ctx, &result, rpcAddr, "getSignStatus", key)
 	if err != nil {
-		return nil, wrapPostError("getSignStatus", err)
+		return nil, c.wrapPostError("getSignStatus", err)
 	}
+	return c.parseSignStatus(&result)
+}
+
+func (c *Client) parseSignStatus(result *DataResultResp) (*SignStatus, error) {
 	if result.Status != successStatus {
-		return nil, newWrongStatusError("getSignStatus", result.Status, "response error "+result.Error)
+		return nil, newStatusError("getSignStatus", result.Status, result.Tip, result.Error)
 	}
 	data := result.Data.Result
 	var signStatus SignStatus
-	err = json.Unmarshal([]byte(data), &signStatus)
+	err := json.Unmarshal([]byte(data), &signStatus)
 	if err != nil {
-		return nil, wrapPostError("getSignStatus", err)
+		return nil, c.wrapPostError("getSignStatus", err)
+	}
+	return &signStatus, nil
+}
+
+// getSignStatusBatch get sign status of keys in one batch request,
+// the returned error is about the whole batch, and signStatuses and errs
+// are aligned with keys, each of them is checked like GetSignStatus.
+func (c *Client) getSignStatusBatch(ctx context.Context, keys []string) (signStatuses []*SignStatus, errs []error, err error) {
+	results := make([]DataResultResp, len(keys))
+	batch := make([]*client.BatchElem, len(keys))
+	for i, key := range keys {
+		batch[i] = &client.BatchElem{
+			Method: c.apiPrefix + "getSignStatus",
+			Params: []interface{}{key},
+			Result: &results[i],
+		}
 	}
+	err = c.httpBatchPostFailover(ctx, batch)
+	if err != nil {
+		return nil, nil, c.wrapPostError("getSignStatus", err)
+	}
+	signStatuses = make([]*SignStatus, len(keys))
+	errs = make([]error, len(keys))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			errs[i] = c.wrapPostError("getSignStatus", elem.Error)
+			continue
+		}
+		signStatuses[i], errs[i] = c.parseSignStatus(&results[i])
+		if errs[i] == nil {
+			signStatuses[i], errs[i] = checkSignStatus(keys[i], signStatuses[i])
+		}
+	}
+	return signStatuses, errs, nil
+}
+
+func checkSignStatus(key string, signStatus *SignStatus) (*SignStatus, error) {
 	switch signStatus.Status {
 	case "Failure":
-		log.Info("getSignStatus Failure", "keyID", key, "status", data)
-		return nil, ErrGetSignStatusFailed
+		log.Info("getSignStatus Failure", "keyID", key, "status", signStatus)
 	case "Timeout":
-		log.Info("getSignStatus Timeout", "keyID", key, "status", data)
-		return nil, ErrGetSignStatusTimeout
+		log.Info("getSignStatus Timeout", "keyID", key, "status", signStatus)
 	case successStatus:
-		return &signStatus, nil
-	default:
-		return nil, newWrongStatusError("getSignStatus", signStatus.Status, "sign status error "+signStatus.Error)
+		return signStatus, nil
 	}
+	return nil, newStatusError("getSignStatus", signStatus.Status, signStatus.Tip, signStatus.Error)
 }
 
 // GetAcceptList get accept list of 'user'
-func GetAcceptList(user string, expiredInterval int64) ([]*SignInfoData, error) {
-	if user == "" && mpcKeyWrapper != nil {
-		user = mpcKeyWrapper.Address.String()
+func (c *Client) GetAcceptList(user string, expiredInterval int64) ([]*SignInfoData, error) {
+	return c.GetAcceptListContext(context.Background(), user, expiredInterval)
+}
+
+// GetAcceptListContext get accept list of 'user' with context
+func (c *Client) GetAcceptListContext(ctx context.Context, user string, expiredInterval int64) ([]*SignInfoData, error) {
+	if user == "" && c.keyWrapper != nil {
+		user = c.user.String()
 	}
-	return getCurNodeSignInfo(user, expiredInterval)
+	return c.getCurNodeSignInfo(ctx, user, expiredInterval)
 }
 
 // GetCurNodeSignInfo call getCurNodeSignInfo
-func GetCurNodeSignInfo(expiredInterval int64) ([]*SignInfoData, error) {
-	return getCurNodeSignInfo(mpcKeyWrapper.Address.String(), expiredInterval)
+func (c *Client) GetCurNodeSignInfo(expiredInterval int64) ([]*SignInfoData, error) {
+	return c.GetCurNodeSignInfoContext(context.Background(), expiredInterval)
+}
+
+// GetCurNodeSignInfoContext call getCurNodeSignInfo with context
+func (c *Client) GetCurNodeSignInfoContext(ctx context.Context, expiredInterval int64) ([]*SignInfoData, error) {
+	return c.getCurNodeSignInfo(ctx, c.user.String(), expiredInterval)
 }
 
 // filter out invalid sign info and
 // filter out expired sign info if `expiredInterval` is greater than 0
-func getCurNodeSignInfo(user string, expiredInterval int64) ([]*SignInfoData, error) {
+func (c *Client) getCurNodeSignInfo(ctx context.Context, user string, expiredInterval int64) ([]*SignInfoData, error) {
 	log.Trace("call getCurNodeSignInfo", "user", user, "expiredInterval", expiredInterval)
 	var result SignInfoResp
-	err := httpPost(&result, "getCurNodeSignInfo", user)
+	err := c.httpPostFailover(ctx, &result, "", "getCurNodeSignInfo", user)
 	if err != nil {
-		return nil, wrapPostError("getCurNodeSignInfo", err)
+		return nil, c.wrapPostError("getCurNodeSignInfo", err)
 	}
 	if result.Status != successStatus {
-		return nil, newWrongStatusError("getCurNodeSignInfo", result.Status, result.Error)
+		return nil, newStatusError("getCurNodeSignInfo", result.Status, result.Tip, result.Error)
 	}
 	log.Trace("call getCurNodeSignInfo success", "user", user, "count", len(result.Data))
 	signInfoSortedSlice := make(SignInfoSortedSlice, 0, len(result.Data))
@@ -143,79 +294,109 @@ func getCurNodeSignInfo(user string, expiredInterval int64) ([]*SignInfoData, er
 }
 
 // Sign call sign
-func Sign(raw, rpcAddr string) (string, error) {
+func (c *Client) Sign(raw, rpcAddr string) (string, error) {
+	return c.SignContext(context.Background(), raw, rpcAddr)
+}
+
+// SignContext call sign with context
+func (c *Client) SignContext(ctx context.Context, raw, rpcAddr string) (string, error) {
 	var result DataResultResp
-	err := httpPostTo(&result, rpcAddr, "sign", raw)
+	err := c.httpPostTo(ctx, &result, rpcAddr, "sign", raw)
 	if err != nil {
-		return "", wrapPostError("sign", err)
+		return "", c.wrapPostError("sign", err)
 	}
 	if result.Status != successStatus {
-		return "", newWrongStatusError("sign", result.Status, result.Error)
+		return "", newStatusError("sign", result.Status, result.Tip, result.Error)
 	}
 	return result.Data.Result, nil
 }
 
 // AcceptSign call acceptSign
-func AcceptSign(raw string) (string, error) {
+func (c *Client) AcceptSign(raw string) (string, error) {
+	return c.AcceptSignContext(context.Background(), raw)
+}
+
+// AcceptSignContext call acceptSign with context
+func (c *Client) AcceptSignContext(ctx context.Context, raw string) (string, error) {
 	var result DataResultResp
-	err := httpPost(&result, "acceptSign", raw)
+	err := c.httpPost(ctx, &result, "acceptSign", raw)
 	if err != nil {
-		return "", wrapPostError("acceptSign", err)
+		return "", c.wrapPostError("acceptSign", err)
 	}
 	if result.Status != successStatus {
-		return "", newWrongStatusError("acceptSign", result.Status, result.Error)
+		return "", newStatusError("acceptSign", result.Status, result.Tip, result.Error)
 	}
 	return result.Data.Result, nil
 }
 
 // GetGroupByID call getGroupByID
-func GetGroupByID(groupID, rpcAddr string) (*GroupInfo, error) {
+func (c *Client) GetGroupByID(groupID, rpcAddr string) (*GroupInfo, error) {
+	return c.GetGroupByIDContext(context.Background(), groupID, rpcAddr)
+}
+
+// GetGroupByIDContext call getGroupByID with context
+func (c *Client) GetGroupByIDContext(ctx context.Context, groupID, rpcAddr string) (*GroupInfo, error) {
 	var result GetGroupByIDResp
-	err := httpPostTo(&result, rpcAddr, "getGroupByID", groupID)
+	err := c.httpPostFailover(ctx, &result, rpcAddr, "getGroupByID", groupID)
 	if err != nil {
-		return nil, wrapPostError("getGroupByID", err)
+		return nil, c.wrapPostError("getGroupByID", err)
 	}
 	if result.Status != successStatus {
-		return nil, newWrongStatusError("getGroupByID", result.Status, result.Error)
+		return nil, newStatusError("getGroupByID", result.Status, result.Tip, result.Error)
 	}
 	return result.Data, nil
 }
 
 // ReqDcrmAddr call reqDcrmAddr
-func ReqDcrmAddr(raw, rpcAddr string) (string, error) {
+func (c *Client) ReqDcrmAddr(raw, rpcAddr string) (string, error) {
+	return c.ReqDcrmAddrContext(context.Background(), raw, rpcAddr)
+}
+
+// ReqDcrmAddrContext call reqDcrmAddr with context
+func (c *Client) ReqDcrmAddrContext(ctx context.Context, raw, rpcAddr string) (string, error) {
 	var result DataResultResp
-	err := httpPostTo(&result, rpcAddr, "reqDcrmAddr", raw)
+	err := c.httpPostTo(ctx, &result, rpcAddr, "reqDcrmAddr", raw)
 	if err != nil {
-		return "", wrapPostError("reqDcrmAddr", err)
+		return "", c.wrapPostError("reqDcrmAddr", err)
 	}
 	if result.Status != successStatus {
-		return "", newWrongStatusError("reqDcrmAddr", result.Status, result.Error)
+		return "", newStatusError("reqDcrmAddr", result.Status, result.Tip, result.Error)
 	}
 	return result.Data.Result, nil
 }
 
 // AcceptReqAddr call acceptReqAddr
-func AcceptReqAddr(raw string) (string, error) {
+func (c *Client) AcceptReqAddr(raw string) (string, error) {
+	return c.AcceptReqAddrContext(context.Background(), raw)
+}
+
+// AcceptReqAddrContext call acceptReqAddr with context
+func (c *Client) AcceptReqAddrContext(ctx context.Context, raw string) (string, error) {
 	var result DataResultResp
-	err := httpPost(&result, "acceptReqAddr", raw)
+	err := c.httpPost(ctx, &result, "acceptReqAddr", raw)
 	if err != nil {
-		return "", wrapPostError("acceptReqAddr", err)
+		return "", c.wrapPostError("acceptReqAddr", err)
 	}
 	if result.Status != successStatus {
-		return "", newWrongStatusError("acceptReqAddr", result.Status, result.Error)
+		return "", newStatusError("acceptReqAddr", result.Status, result.Tip, result.Error)
 	}
 	return result.Data.Result, nil
 }
 
 // GetReqAddrNonce call getReqAddrNonce
-func GetReqAddrNonce(mpcUser, rpcAddr string) (uint64, error) {
+func (c *Client) GetReqAddrNonce(mpcUser, rpcAddr string) (uint64, error) {
+	return c.GetReqAddrNonceContext(context.Background(), mpcUser, rpcAddr)
+}
+
+// GetReqAddrNonceContext call getReqAddrNonce with context
+func (c *Client) GetReqAddrNonceContext(ctx context.Context, mpcUser, rpcAddr string) (uint64, error) {
 	var result DataResultResp
-	err := httpPostTo(&result, rpcAddr, "getReqAddrNonce", mpcUser)
+	err := c.httpPostFailover(ctx, &result, rpcAddr, "getReqAddrNonce", mpcUser)
 	if err != nil {
-		return 0, wrapPostError("getReqAddrNonce", err)
+		return 0, c.wrapPostError("getReqAddrNonce", err)
 	}
 	if result.Status != successStatus {
-		return 0, newWrongStatusError("getReqAddrNonce", result.Status, result.Error)
+		return 0, newStatusError("getReqAddrNonce", result.Status, result.Tip, result.Error)
 	}
 	bi, err := GetBigIntFromStr(result.Data.Result)
 	if err != nil {
@@ -225,59 +406,71 @@ func GetReqAddrNonce(mpcUser, rpcAddr string) (uint64, error) {
 }
 
 // GetReqAddrStatus call getReqAddrStatus
-func GetReqAddrStatus(key, rpcAddr string) (*ReqAddrStatus, error) {
+func (c *Client) GetReqAddrStatus(key, rpcAddr string) (*ReqAddrStatus, error) {
+	return c.GetReqAddrStatusContext(context.Background(), key, rpcAddr)
+}
+
+// GetReqAddrStatusContext call getReqAddrStatus with context
+func (c *Client) GetReqAddrStatusContext(ctx context.Context, key, rpcAddr string) (*ReqAddrStatus, error) {
 	var result DataResultResp
-	err := httpPostTo(&result, rpcAddr, "getReqAddrStatus", key)
+	err := c.httpPostFailover(ctx, &result, rpcAddr, "getReqAddrStatus", key)
 	if err != nil {
-		return nil, wrapPostError("getReqAddrStatus", err)
+		return nil, c.wrapPostError("getReqAddrStatus", err)
 	}
 	if result.Status != successStatus {
-		return nil, newWrongStatusError("getReqAddrStatus", result.Status, "response error "+result.Error)
+		return nil, newStatusError("getReqAddrStatus", result.Status, result.Tip, result.Error)
 	}
 	data := result.Data.Result
 	var reqAddrStatus ReqAddrStatus
 	err = json.Unmarshal([]byte(data), &reqAddrStatus)
 	if err != nil {
-		return nil, wrapPostError("getReqAddrStatus", err)
+		return nil, c.wrapPostError("getReqAddrStatus", err)
 	}
 	switch reqAddrStatus.Status {
 	case "Failure":
 		log.Info("getReqAddrStatus Failure", "keyID", key, "status", data)
-		return nil, ErrGetDKGStatusFailed
 	case "Timeout":
 		log.Info("getReqAddrStatus Timeout", "keyID", key, "status", data)
-		return nil, ErrGetDKGStatusTimeout
 	case successStatus:
 		return &reqAddrStatus, nil
-	default:
-		return nil, newWrongStatusError("getReqAddrStatus", reqAddrStatus.Status, "sign status error "+reqAddrStatus.Error)
 	}
+	return nil, newStatusError("getReqAddrStatus", reqAddrStatus.Status, reqAddrStatus.Tip, reqAddrStatus.Error)
 }
 
 // GetDKGAcceptList get dkg accept list
-func GetDKGAcceptList(user string, expiredInterval int64) ([]*ReqAddrInfoData, error) {
-	if user == "" && mpcKeyWrapper != nil {
-		user = mpcKeyWrapper.Address.String()
+func (c *Client) GetDKGAcceptList(user string, expiredInterval int64) ([]*ReqAddrInfoData, error) {
+	return c.GetDKGAcceptListContext(context.Background(), user, expiredInterval)
+}
+
+// GetDKGAcceptListContext get dkg accept list with context
+func (c *Client) GetDKGAcceptListContext(ctx context.Context, user string, expiredInterval int64) ([]*ReqAddrInfoData, error) {
+	if user == "" && c.keyWrapper != nil {
+		user = c.user.String()
 	}
-	return getCurNodeReqAddrInfo(user, expiredInterval)
+	return c.getCurNodeReqAddrInfo(ctx, user, expiredInterval)
 }
 
 // GetCurNodeReqAddrInfo call getCurNodeReqAddrInfo
-func GetCurNodeReqAddrInfo(expiredInterval int64) ([]*ReqAddrInfoData, error) {
-	return getCurNodeReqAddrInfo(mpcKeyWrapper.Address.String(), expiredInterval)
+func (c *Client) GetCurNodeReqAddrInfo(expiredInterval int64) ([]*ReqAddrInfoData, error) {
+	return c.GetCurNodeReqAddrInfoContext(context.Background(), expiredInterval)
+}
+
+// GetCurNodeReqAddrInfoContext call getCurNodeReqAddrInfo with context
+func (c *Client) GetCurNodeReqAddrInfoContext(ctx context.Context, expiredInterval int64) ([]*ReqAddrInfoData, error) {
+	return c.getCurNodeReqAddrInfo(ctx, c.user.String(), expiredInterval)
 }
 
 // filter out invalid reqAddr info and
 // filter out expired reqAddr info if `expiredInterval` is greater than 0
-func getCurNodeReqAddrInfo(user string, expiredInterval int64) ([]*ReqAddrInfoData, error) {
+func (c *Client) getCurNodeReqAddrInfo(ctx context.Context, user string, expiredInterval int64) ([]*ReqAddrInfoData, error) {
 	log.Trace("call getCurNodeReqAddrInfo", "user", user, "expiredInterval", expiredInterval)
 	var result ReqAddrInfoResp
-	err := httpPost(&result, "getCurNodeReqAddrInfo", user)
+	err := c.httpPostFailover(ctx, &result, "", "getCurNodeReqAddrInfo", user)
 	if err != nil {
-		return nil, wrapPostError("getCurNodeReqAddrInfo", err)
+		return nil, c.wrapPostError("getCurNodeReqAddrInfo", err)
 	}
 	if result.Status != successStatus {
-		return nil, newWrongStatusError("getCurNodeReqAddrInfo", result.Status, result.Error)
+		return nil, newStatusError("getCurNodeReqAddrInfo", result.Status, result.Tip, result.Error)
 	}
 	log.Trace("call getCurNodeReqAddrInfo success", "user", user, "count", len(result.Data))
 	reqAddrInfoSortedSlice := make(ReqAddrInfoSortedSlice, 0, len(result.Data))
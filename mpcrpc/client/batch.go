@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/anyswap/mpc-client/log"
+)
+
+var errBatchResponseMismatch = errors.New("batch response mismatch")
+
+// BatchElem is an element of a batch request,
+// Result and Error are set after the batch request is finished.
+type BatchElem struct {
+	Method string
+	Params []interface{}
+	Result interface{}
+	Error  error
+}
+
+// BatchRPCPostWithContext post many json rpc requests in one http request,
+// the returned error is about the whole batch,
+// the result or error of each request is set into its element.
+func BatchRPCPostWithContext(ctx context.Context, timeout int, url string, batch []*BatchElem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	reqBody := make([]*RequestBody, len(batch))
+	for i, elem := range batch {
+		elem.Error = nil
+		params := elem.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		reqBody[i] = &RequestBody{
+			Version: "2.0",
+			Method:  elem.Method,
+			Params:  params,
+			ID:      i + 1,
+		}
+	}
+	resp, err := HTTPPostWithContext(ctx, url, reqBody, nil, nil, timeout)
+	if err != nil {
+		log.Trace("post batch rpc error", "url", url, "count", len(batch), "err", err)
+		return &TransportError{URL: url, Err: err}
+	}
+	err = getBatchResultFromJSONResponse(url, batch, resp)
+	if err != nil {
+		log.Trace("post batch rpc error", "url", url, "count", len(batch), "err", err)
+	}
+	return err
+}
+
+func getBatchResultFromJSONResponse(url string, batch []*BatchElem, resp *http.Response) error {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	const maxReadContentLength int64 = 1024 * 1024 * 10 // 10M
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxReadContentLength))
+	if err != nil {
+		return &TransportError{URL: url, Err: fmt.Errorf("read body error: %w", err)}
+	}
+	if resp.StatusCode != 200 {
+		return &HTTPStatusError{URL: url, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	if len(body) == 0 {
+		return fmt.Errorf("empty response body")
+	}
+
+	var jsonResps []*jsonrpcResponse
+	err = json.Unmarshal(body, &jsonResps)
+	if err != nil {
+		// server which does not support batch may answer a single error object
+		var jsonResp jsonrpcResponse
+		if json.Unmarshal(body, &jsonResp) == nil && jsonResp.Error != nil {
+			return fmt.Errorf("return error: %w", jsonResp.Error)
+		}
+		return fmt.Errorf("unmarshal body error, body is \"%v\" err=\"%w\"", string(body), err)
+	}
+
+	answered := make([]bool, len(batch))
+	for _, jsonResp := range jsonResps {
+		var id int
+		if err = json.Unmarshal(jsonResp.ID, &id); err != nil || id < 1 || id > len(batch) || answered[id-1] {
+			return fmt.Errorf("%w, unknown id %v", errBatchResponseMismatch, string(jsonResp.ID))
+		}
+		answered[id-1] = true
+		elem := batch[id-1]
+		if jsonResp.Error != nil {
+			elem.Error = fmt.Errorf("return error: %w", jsonResp.Error)
+			continue
+		}
+		if err = json.Unmarshal(jsonResp.Result, elem.Result); err != nil {
+			elem.Error = fmt.Errorf("unmarshal result error: %w", err)
+		}
+	}
+	for i, ok := range answered {
+		if !ok {
+			batch[i].Error = fmt.Errorf("%w, no response of id %v", errBatchResponseMismatch, i+1)
+		}
+	}
+	return nil
+}
@@ -50,7 +50,12 @@ func createHTTPClient() *http.Client {
 
 // HTTPGet http get
 func HTTPGet(url string, params, headers map[string]string, timeout int) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(httpCtx, http.MethodGet, url, nil)
+	return HTTPGetWithContext(httpCtx, url, params, headers, timeout)
+}
+
+// HTTPGetWithContext http get with context
+func HTTPGetWithContext(ctx context.Context, url string, params, headers map[string]string, timeout int) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +68,12 @@ func HTTPGet(url string, params, headers map[string]string, timeout int) (*http.
 
 // HTTPPost http post
 func HTTPPost(url string, body interface{}, params, headers map[string]string, timeout int) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(httpCtx, http.MethodPost, url, nil)
+	return HTTPPostWithContext(httpCtx, url, body, params, headers, timeout)
+}
+
+// HTTPPostWithContext http post with context
+func HTTPPostWithContext(ctx context.Context, url string, body interface{}, params, headers map[string]string, timeout int) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +89,12 @@ func HTTPPost(url string, body interface{}, params, headers map[string]string, t
 
 // HTTPRawPost http raw post
 func HTTPRawPost(url, body string, params, headers map[string]string, timeout int) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(httpCtx, http.MethodPost, url, nil)
+	return HTTPRawPostWithContext(httpCtx, url, body, params, headers, timeout)
+}
+
+// HTTPRawPostWithContext http raw post with context
+func HTTPRawPostWithContext(ctx context.Context, url, body string, params, headers map[string]string, timeout int) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +159,8 @@ func doRequest(req *http.Request, timeoutSeconds int) (*http.Response, error) {
 		}
 		return client.Do(req)
 	}
-	httpClient.Timeout = timeout
-	return httpClient.Do(req)
+	// copy the shared client to set timeout, as requests may be sent concurrently
+	client := *httpClient
+	client.Timeout = timeout
+	return client.Do(req)
 }
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"fmt"
+)
+
+// JSONRPCError json-rpc error object returned by server
+type JSONRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error impl error interface
+func (err *JSONRPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d, %s", err.Code, err.Message)
+}
+
+// TransportError the request does not get a response,
+// eg. connection refused, timeout, canceled.
+type TransportError struct {
+	URL string
+	Err error
+}
+
+// Error impl error interface
+func (err *TransportError) Error() string {
+	return fmt.Sprintf("transport error: %v (url: %v)", err.Err, err.URL)
+}
+
+// Unwrap returns the underlying error
+func (err *TransportError) Unwrap() error {
+	return err.Err
+}
+
+// HTTPStatusError the response http status code is not 200
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+// Error impl error interface
+func (err *HTTPStatusError) Error() string {
+	return fmt.Sprintf("wrong response status %v. message: %v (url: %v)", err.StatusCode, err.Body, err.URL)
+}
@@ -23,7 +23,7 @@ func RPCGetWithTimeout(result interface{}, url string, timeout int) error {
 func RPCGetRequest(result interface{}, url string, params, headers map[string]string, timeout int) error {
 	resp, err := HTTPGet(url, params, headers, timeout)
 	if err != nil {
-		return fmt.Errorf("GET request error: %w (url: %v, params: %v)", err, url, params)
+		return &TransportError{URL: url, Err: fmt.Errorf("GET request error: %w (params: %v)", err, params)}
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -31,7 +31,7 @@ func RPCGetRequest(result interface{}, url string, params, headers map[string]st
 
 	if resp.StatusCode != 200 {
 		log.Trace("get rpc status error", "url", url, "status", resp.StatusCode)
-		return fmt.Errorf("error response status: %v (url: %v)", resp.StatusCode, url)
+		return &HTTPStatusError{URL: url, StatusCode: resp.StatusCode}
 	}
 
 	const maxReadContentLength int64 = 1024 * 1024 * 10 // 10M
@@ -61,7 +61,7 @@ func RPCRawGetWithTimeout(url string, timeout int) (string, error) {
 func RPCRawGetRequest(url string, params, headers map[string]string, timeout int) (string, error) {
 	resp, err := HTTPGet(url, params, headers, timeout)
 	if err != nil {
-		return "", fmt.Errorf("GET request error: %w (url: %v, params: %v)", err, url, params)
+		return "", &TransportError{URL: url, Err: fmt.Errorf("GET request error: %w (params: %v)", err, params)}
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -75,7 +75,7 @@ func RPCRawGetRequest(url string, params, headers map[string]string, timeout int
 
 	if resp.StatusCode != 200 {
 		log.Trace("get rpc status error", "url", url, "status", resp.StatusCode)
-		return "", fmt.Errorf("wrong response status %v. message: %v", resp.StatusCode, string(body))
+		return "", &HTTPStatusError{URL: url, StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	return string(body), nil
 }
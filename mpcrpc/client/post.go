@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -56,6 +57,12 @@ func RPCPostWithTimeout(timeout int, result interface{}, url, method string, par
 	return RPCPostRequest(url, req, result)
 }
 
+// RPCPostWithContext rpc post with context and timeout
+func RPCPostWithContext(ctx context.Context, timeout int, result interface{}, url, method string, params ...interface{}) error {
+	req := NewRequestWithTimeoutAndID(timeout, defaultRequestID, method, params...)
+	return RPCPostRequestWithContext(ctx, url, req, result)
+}
+
 // RPCPostWithTimeoutAndID rpc post with timeout and id
 func RPCPostWithTimeoutAndID(result interface{}, timeout, id int, url, method string, params ...interface{}) error {
 	req := NewRequestWithTimeoutAndID(timeout, id, method, params...)
@@ -70,54 +77,49 @@ type RequestBody struct {
 	ID      int         `json:"id"`
 }
 
-type jsonError struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-}
-
-func (err *jsonError) Error() string {
-	return fmt.Sprintf("json-rpc error %d, %s", err.Code, err.Message)
-}
-
 type jsonrpcResponse struct {
 	Version string          `json:"jsonrpc,omitempty"`
 	ID      json.RawMessage `json:"id,omitempty"`
-	Error   *jsonError      `json:"error,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
 }
 
 // RPCPostRequest rpc post request
 func RPCPostRequest(url string, req *Request, result interface{}) error {
+	return RPCPostRequestWithContext(httpCtx, url, req, result)
+}
+
+// RPCPostRequestWithContext rpc post request with context
+func RPCPostRequestWithContext(ctx context.Context, url string, req *Request, result interface{}) error {
 	reqBody := &RequestBody{
 		Version: "2.0",
 		Method:  req.Method,
 		Params:  req.Params,
 		ID:      req.ID,
 	}
-	resp, err := HTTPPost(url, reqBody, nil, nil, req.Timeout)
+	resp, err := HTTPPostWithContext(ctx, url, reqBody, nil, nil, req.Timeout)
 	if err != nil {
 		log.Trace("post rpc error", "url", url, "request", req, "err", err)
-		return err
+		return &TransportError{URL: url, Err: err}
 	}
-	err = getResultFromJSONResponse(result, resp)
+	err = getResultFromJSONResponse(url, result, resp)
 	if err != nil {
 		log.Trace("post rpc error", "url", url, "request", req, "err", err)
 	}
 	return err
 }
 
-func getResultFromJSONResponse(result interface{}, resp *http.Response) error {
+func getResultFromJSONResponse(url string, result interface{}, resp *http.Response) error {
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 	const maxReadContentLength int64 = 1024 * 1024 * 10 // 10M
 	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxReadContentLength))
 	if err != nil {
-		return fmt.Errorf("read body error: %w", err)
+		return &TransportError{URL: url, Err: fmt.Errorf("read body error: %w", err)}
 	}
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("wrong response status %v. message: %v", resp.StatusCode, string(body))
+		return &HTTPStatusError{URL: url, StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	if len(body) == 0 {
 		return fmt.Errorf("empty response body")
@@ -147,7 +149,7 @@ func RPCRawPost(url, body string) (string, error) {
 func RPCRawPostWithTimeout(url, reqBody string, timeout int) (string, error) {
 	resp, err := HTTPRawPost(url, reqBody, nil, nil, timeout)
 	if err != nil {
-		return "", err
+		return "", &TransportError{URL: url, Err: err}
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -155,10 +157,10 @@ func RPCRawPostWithTimeout(url, reqBody string, timeout int) (string, error) {
 	const maxReadContentLength int64 = 1024 * 1024 * 10 // 10M
 	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxReadContentLength))
 	if err != nil {
-		return "", fmt.Errorf("read body error: %w", err)
+		return "", &TransportError{URL: url, Err: fmt.Errorf("read body error: %w", err)}
 	}
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("wrong response status %v. message: %v", resp.StatusCode, string(body))
+		return "", &HTTPStatusError{URL: url, StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	return string(body), nil
 }
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+)
+
+const (
+	defaultBackoffMultiplier = 2
+)
+
+// RetryPolicy retry policy of rpc calls and status polling
+type RetryPolicy struct {
+	// MaxAttempts is the max attempts count including the first one,
+	// 1 means no retry, 0 means no limit (bounded by ctx only).
+	MaxAttempts int
+	// InitialBackoff is the waiting time before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper limit of waiting time, 0 means no limit
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff grows by after each retry,
+	// 1 means constant backoff, 0 means the default value 2.
+	Multiplier float64
+	// Jitter is the random factor in [0, 1] applied to backoff,
+	// eg. 0.2 means the backoff is randomized in [0.8, 1.2] times.
+	Jitter float64
+	// Retryable classifies which errors are transient,
+	// nil means IsRetryableError.
+	Retryable func(error) bool
+}
+
+// NoRetry retry policy which never retry
+var NoRetry = &RetryPolicy{MaxAttempts: 1}
+
+// Backoff returns the waiting time after the attempt-th (start from 1) attempt
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		backoff *= 1 - jitter + 2*jitter*rand.Float64() // nolint:gosec // ok
+	}
+	return time.Duration(backoff)
+}
+
+// CanAttempt returns whether attempts count is not exceeded
+func (p *RetryPolicy) CanAttempt(attempt int) bool {
+	return p.MaxAttempts <= 0 || attempt <= p.MaxAttempts
+}
+
+// IsRetryable returns whether err is transient and worth retrying
+func (p *RetryPolicy) IsRetryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// WithRetryable returns a copy of the policy using the retryable classifier
+// if the policy itself does not specify one.
+func (p *RetryPolicy) WithRetryable(retryable func(error) bool) *RetryPolicy {
+	if p.Retryable != nil {
+		return p
+	}
+	cp := *p
+	cp.Retryable = retryable
+	return &cp
+}
+
+// Do calls fn until it succeeds, returns non retryable error,
+// reaches max attempts, or ctx is done.
+func (p *RetryPolicy) Do(ctx context.Context, fn func() error) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || ctx.Err() != nil || !p.CanAttempt(attempt+1) || !p.IsRetryable(err) {
+			return err
+		}
+		timer := time.NewTimer(p.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// IsRetryableError returns true for transient errors, which are
+// transport errors, http status 429 and 5xx.
+func IsRetryableError(err error) bool {
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == 429 || statusErr.StatusCode >= 500
+	}
+	return false
+}
+
+// IsDialError returns true if the request is not sent because of dial error,
+// it is used to retry non idempotent requests safely.
+func IsDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// RPCPostWithRetry rpc post with context, timeout and retry policy
+func RPCPostWithRetry(ctx context.Context, policy *RetryPolicy, timeout int, result interface{}, url, method string, params ...interface{}) error {
+	if policy == nil {
+		policy = NoRetry
+	}
+	return policy.Do(ctx, func() error {
+		return RPCPostWithContext(ctx, timeout, result, url, method, params...)
+	})
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Backoff(1))
+	assert.Equal(t, 2*time.Second, p.Backoff(2))
+	assert.Equal(t, 4*time.Second, p.Backoff(3))
+	assert.Equal(t, 5*time.Second, p.Backoff(4))
+
+	p = &RetryPolicy{InitialBackoff: time.Second, Multiplier: 1}
+	assert.Equal(t, time.Second, p.Backoff(10))
+
+	p = &RetryPolicy{InitialBackoff: time.Second, Jitter: 0.2}
+	for i := 0; i < 10; i++ {
+		backoff := p.Backoff(1)
+		assert.True(t, backoff >= 800*time.Millisecond && backoff <= 1200*time.Millisecond)
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	transportErr := &TransportError{URL: "http://127.0.0.1:1234", Err: errors.New("connection refused")}
+
+	calls := 0
+	err := p.Do(context.Background(), func() error {
+		calls++
+		return transportErr
+	})
+	assert.Equal(t, transportErr, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = p.Do(context.Background(), func() error {
+		calls++
+		if calls < 2 {
+			return &HTTPStatusError{StatusCode: 503}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	err = p.Do(context.Background(), func() error {
+		calls++
+		return &JSONRPCError{Code: -32000, Message: "nonce is too low"}
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = p.WithRetryable(IsDialError).Do(context.Background(), func() error {
+		calls++
+		return transportErr
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+}
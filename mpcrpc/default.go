@@ -0,0 +1,150 @@
+package mpcrpc
+
+import "context"
+
+// The following functions are shims of the default client initialized by Init.
+
+// SignWithKey sign by mpc node user with private key
+func SignWithKey(message []byte) ([]byte, error) {
+	return defaultClient.SignWithKey(message)
+}
+
+// SignContent sign content
+func SignContent(content []byte) (signature []byte, err error) {
+	return defaultClient.SignContent(content)
+}
+
+// DoSignOne mpc sign single msgHash with context msgContext
+func DoSignOne(signPubkey, msgHash, msgContext string) (keyID string, rsvs []string, err error) {
+	return defaultClient.DoSignOne(signPubkey, msgHash, msgContext)
+}
+
+// DoSign mpc sign msgHash with context msgContext
+func DoSign(signPubkey string, msgHash, msgContext []string) (keyID string, rsvs []string, err error) {
+	return defaultClient.DoSign(signPubkey, msgHash, msgContext)
+}
+
+// DoSignContext mpc sign msgHash with context msgContext, abort if ctx is canceled
+func DoSignContext(ctx context.Context, signPubkey string, msgHash, msgContext []string) (keyID string, rsvs []string, err error) {
+	return defaultClient.DoSignContext(ctx, signPubkey, msgHash, msgContext)
+}
+
+// SubmitSign submit mpc sign request and returns the handle immediately
+func SubmitSign(signPubkey string, msgHash, msgContext []string) (*SignHandle, error) {
+	return defaultClient.SubmitSign(signPubkey, msgHash, msgContext)
+}
+
+// WatchSign returns the handle of an already submitted sign
+func WatchSign(keyID string) *SignHandle {
+	return defaultClient.WatchSign(keyID)
+}
+
+// GetSignStatusByKeyID get sign status by keyID
+func GetSignStatusByKeyID(keyID string) (rsvs []string, err error) {
+	return defaultClient.GetSignStatusByKeyID(keyID)
+}
+
+// BuildMPCRawTx build mpc raw tx
+func BuildMPCRawTx(nonce uint64, payload []byte) (string, error) {
+	return defaultClient.BuildMPCRawTx(nonce, payload)
+}
+
+// DoDKG mpc pubkic key generation
+func DoDKG(enodeSigs []string) (keyID string, pubkey string, err error) {
+	return defaultClient.DoDKG(enodeSigs)
+}
+
+// DoDKGContext mpc pubkic key generation with context
+func DoDKGContext(ctx context.Context, enodeSigs []string) (keyID string, pubkey string, err error) {
+	return defaultClient.DoDKGContext(ctx, enodeSigs)
+}
+
+// DoAcceptSign accept sign
+func DoAcceptSign(keyID, agreeResult string, msgHash, msgContext []string) (string, error) {
+	return defaultClient.DoAcceptSign(keyID, agreeResult, msgHash, msgContext)
+}
+
+// DoAcceptReqAddr accept request address
+func DoAcceptReqAddr(keyID, agreeResult string) (string, error) {
+	return defaultClient.DoAcceptReqAddr(keyID, agreeResult)
+}
+
+// GetEnode call getEnode
+func GetEnode(rpcAddr string) (string, error) {
+	return defaultClient.GetEnode(rpcAddr)
+}
+
+// GetSignNonce call getSignNonce
+func GetSignNonce(mpcUser, rpcAddr string) (uint64, error) {
+	return defaultClient.GetSignNonce(mpcUser, rpcAddr)
+}
+
+// GetSignStatus call getSignStatus
+func GetSignStatus(key, rpcAddr string) (*SignStatus, error) {
+	return defaultClient.GetSignStatus(key, rpcAddr)
+}
+
+// GetSignStatusContext call getSignStatus with context
+func GetSignStatusContext(ctx context.Context, key, rpcAddr string) (*SignStatus, error) {
+	return defaultClient.GetSignStatusContext(ctx, key, rpcAddr)
+}
+
+// GetAcceptList get accept list of 'user'
+func GetAcceptList(user string, expiredInterval int64) ([]*SignInfoData, error) {
+	return defaultClient.GetAcceptList(user, expiredInterval)
+}
+
+// GetCurNodeSignInfo call getCurNodeSignInfo
+func GetCurNodeSignInfo(expiredInterval int64) ([]*SignInfoData, error) {
+	return defaultClient.GetCurNodeSignInfo(expiredInterval)
+}
+
+// Sign call sign
+func Sign(raw, rpcAddr string) (string, error) {
+	return defaultClient.Sign(raw, rpcAddr)
+}
+
+// AcceptSign call acceptSign
+func AcceptSign(raw string) (string, error) {
+	return defaultClient.AcceptSign(raw)
+}
+
+// GetGroupByID call getGroupByID
+func GetGroupByID(groupID, rpcAddr string) (*GroupInfo, error) {
+	return defaultClient.GetGroupByID(groupID, rpcAddr)
+}
+
+// ReqDcrmAddr call reqDcrmAddr
+func ReqDcrmAddr(raw, rpcAddr string) (string, error) {
+	return defaultClient.ReqDcrmAddr(raw, rpcAddr)
+}
+
+// AcceptReqAddr call acceptReqAddr
+func AcceptReqAddr(raw string) (string, error) {
+	return defaultClient.AcceptReqAddr(raw)
+}
+
+// GetReqAddrNonce call getReqAddrNonce
+func GetReqAddrNonce(mpcUser, rpcAddr string) (uint64, error) {
+	return defaultClient.GetReqAddrNonce(mpcUser, rpcAddr)
+}
+
+// GetReqAddrStatus call getReqAddrStatus
+func GetReqAddrStatus(key, rpcAddr string) (*ReqAddrStatus, error) {
+	return defaultClient.GetReqAddrStatus(key, rpcAddr)
+}
+
+// GetDKGAcceptList get dkg accept list
+func GetDKGAcceptList(user string, expiredInterval int64) ([]*ReqAddrInfoData, error) {
+	return defaultClient.GetDKGAcceptList(user, expiredInterval)
+}
+
+// GetCurNodeReqAddrInfo call getCurNodeReqAddrInfo
+func GetCurNodeReqAddrInfo(expiredInterval int64) ([]*ReqAddrInfoData, error) {
+	return defaultClient.GetCurNodeReqAddrInfo(expiredInterval)
+}
+
+// ResetNonce makes the next sign and dkg requests resync the nonce from mpc server
+func ResetNonce() {
+	defaultClient.ResetNonce()
+}
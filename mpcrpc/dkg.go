@@ -1,6 +1,7 @@
 package mpcrpc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
@@ -13,72 +14,88 @@ var (
 	errDKGWithoutSigs     = errors.New("dkg without enode sigs")
 	errDoDKGFailed        = errors.New("do dkg failed")
 	errGetDKGResultFailed = errors.New("get dkg result failed")
+	errEmptyDKGResult     = errors.New("empty dkg result")
 )
 
 // DoDKG mpc pubkic key generation
-func DoDKG(enodeSigs []string) (keyID string, pubkey string, err error) {
+func (c *Client) DoDKG(enodeSigs []string) (keyID string, pubkey string, err error) {
+	return c.DoDKGContext(context.Background(), enodeSigs)
+}
+
+// DoDKGContext mpc pubkic key generation with context
+func (c *Client) DoDKGContext(ctx context.Context, enodeSigs []string) (keyID string, pubkey string, err error) {
 	log.Info("mpc DoDKG begin", "enodeSigs", enodeSigs)
 	if len(enodeSigs) == 0 {
 		return "", "", errDKGWithoutSigs
 	}
-	keyID, pubkey, err = doDKGImpl(enodeSigs)
+	keyID, pubkey, err = c.doDKGImpl(ctx, enodeSigs)
 	if err != nil {
 		log.Error("mpc DoDKG failed", "err", err)
-		return "", "", errDoDKGFailed
+		return "", "", wrapError(errDoDKGFailed, err)
 	}
 	log.Info("mpc DoDKG success", "keyID", keyID, "pubkey", pubkey)
 	return keyID, pubkey, nil
 }
 
-func doDKGImpl(enodeSigs []string) (keyID string, pubkey string, err error) {
-	nonce, err := GetReqAddrNonce(mpcUser.String(), mpcRPCAddress)
-	if err != nil {
-		return "", "", err
-	}
+func (c *Client) doDKGImpl(ctx context.Context, enodeSigs []string) (keyID string, pubkey string, err error) {
 	txdata := ReqAddrData{
 		TxType:    "REQDCRMADDR",
-		GroupID:   mpcSignGroup,
-		ThresHold: mpcThreshold,
-		Mode:      mpcMode,
+		Keytype:   c.signType,
+		GroupID:   c.signGroup,
+		ThresHold: c.threshold,
+		Mode:      c.mode,
 		TimeStamp: NowMilliStr(),
 		Sigs:      strings.Join(enodeSigs, "|"),
 	}
 	payload, _ := json.Marshal(txdata)
-	rawTX, err := BuildMPCRawTx(nonce, payload)
+
+	// the nonce is fetched from the same endpoint the request is sent to
+	writeAddr := c.getRPCAddress("")
+	err = c.reqAddrNonce.submit(ctx, writeAddr, func(nonce uint64) error {
+		rawTX, errf := c.BuildMPCRawTx(nonce, payload)
+		if errf != nil {
+			return errf
+		}
+		keyID, errf = c.ReqDcrmAddrContext(ctx, rawTX, writeAddr)
+		return errf
+	})
 	if err != nil {
 		return "", "", err
 	}
 
-	rpcAddr := mpcRPCAddress
-	keyID, err = ReqDcrmAddr(rawTX, rpcAddr)
+	rpcAddr := "" // empty rpc address means using the healthy endpoints
+	pubkey, err = c.getDKGResult(ctx, keyID, rpcAddr)
 	if err != nil {
 		return "", "", err
 	}
-
-	pubkey, err = getDKGResult(keyID, rpcAddr)
+	err = CheckPublicKey(c.signType, pubkey)
 	if err != nil {
 		return "", "", err
 	}
 	return keyID, pubkey, nil
 }
 
-func getDKGResult(keyID, rpcAddr string) (pubkey string, err error) {
+func (c *Client) getDKGResult(ctx context.Context, keyID, rpcAddr string) (pubkey string, err error) {
 	log.Info("start get dkg status", "keyID", keyID)
 	var reqAddrStatus *ReqAddrStatus
 	i := 0
-	timer := time.NewTimer(mpcSignTimeout)
+	pollPolicy := c.getPollPolicy(ctx, true)
+	timer := time.NewTimer(c.signTimeout)
 	defer timer.Stop()
 LOOP_GET_DKG_STATUS:
 	for {
 		i++
 		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break LOOP_GET_DKG_STATUS
 		case <-timer.C:
 			if err == nil {
 				err = errSignTimerTimeout
 			}
 			break LOOP_GET_DKG_STATUS
 		default:
-			reqAddrStatus, err = GetReqAddrStatus(keyID, rpcAddr)
+			reqAddrStatus, err = c.GetReqAddrStatusContext(ctx, keyID, rpcAddr)
 			if err == nil {
 				pubkey = reqAddrStatus.PubKey
 				break LOOP_GET_DKG_STATUS
@@ -89,11 +106,17 @@ LOOP_GET_DKG_STATUS:
 				break LOOP_GET_DKG_STATUS
 			}
 		}
-		time.Sleep(1 * time.Second)
+		if !pollPolicy.CanAttempt(i + 1) {
+			break LOOP_GET_DKG_STATUS
+		}
+		sleepContext(ctx, pollPolicy.Backoff(i))
+	}
+	if err == nil && pubkey == "" {
+		err = errEmptyDKGResult
 	}
-	if pubkey == "" || err != nil {
+	if err != nil {
 		log.Info("get dkg status failed", "keyID", keyID, "retryCount", i, "err", err)
-		return "", errGetDKGResultFailed
+		return "", wrapError(errGetDKGResultFailed, err)
 	}
 	log.Info("get dkg status success", "keyID", keyID, "pubkey", pubkey, "retryCount", i)
 	return pubkey, nil
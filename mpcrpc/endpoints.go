@@ -0,0 +1,201 @@
+package mpcrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyswap/mpc-client/log"
+)
+
+// rpc endpoint selecting strategies
+const (
+	// StrategyFirstHealthy always try the endpoints in configured order
+	StrategyFirstHealthy = "first-healthy"
+	// StrategyRoundRobin rotate the first tried endpoint on every read call,
+	// write calls are always sent to the first healthy endpoint
+	StrategyRoundRobin = "round-robin"
+	// StrategyQuorum same as first-healthy, but sign status is queried
+	// from all endpoints and must be agreed by the majority of them
+	StrategyQuorum = "quorum"
+
+	defaultUnhealthyDuration = 30 * time.Second
+)
+
+var (
+	errNoRPCEndpoint = errors.New("no mpc rpc endpoint")
+	errNoQuorum      = errors.New("mpc rpc endpoints do not reach quorum")
+)
+
+type endpoint struct {
+	url            string
+	failures       int
+	unhealthyUntil time.Time
+}
+
+func (ep *endpoint) isHealthy(now time.Time) bool {
+	return ep.failures == 0 || now.After(ep.unhealthyUntil)
+}
+
+// endpointPool tracks the health of mpc rpc endpoints and
+// decides the order of endpoints to try according to strategy.
+type endpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	strategy  string
+	next      int
+
+	unhealthyDuration time.Duration
+}
+
+func newEndpointPool(urls []string, strategy string, unhealthyDuration time.Duration) (*endpointPool, error) {
+	switch strategy {
+	case "":
+		strategy = StrategyFirstHealthy
+	case StrategyFirstHealthy, StrategyRoundRobin, StrategyQuorum:
+	default:
+		return nil, fmt.Errorf("unknown mpc rpc strategy '%v'", strategy)
+	}
+	if unhealthyDuration <= 0 {
+		unhealthyDuration = defaultUnhealthyDuration
+	}
+	pool := &endpointPool{
+		strategy:          strategy,
+		unhealthyDuration: unhealthyDuration,
+	}
+	exist := make(map[string]struct{}, len(urls))
+	for _, url := range urls {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+		if _, ok := exist[url]; ok {
+			continue
+		}
+		exist[url] = struct{}{}
+		pool.endpoints = append(pool.endpoints, &endpoint{url: url})
+	}
+	if len(pool.endpoints) == 0 {
+		return nil, errNoRPCEndpoint
+	}
+	return pool, nil
+}
+
+// urls returns all endpoint urls in configured order
+func (p *endpointPool) urls() []string {
+	if p == nil {
+		return nil
+	}
+	urls := make([]string, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		urls[i] = ep.url
+	}
+	return urls
+}
+
+// candidates returns endpoint urls in the order to try,
+// the healthy ones come first, the unhealthy ones are the last resort.
+func (p *endpointPool) candidates() []string {
+	if p == nil || len(p.endpoints) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	count := len(p.endpoints)
+	start := 0
+	if p.strategy == StrategyRoundRobin {
+		start = p.next % count
+		p.next = (p.next + 1) % count
+	}
+
+	now := time.Now()
+	healthy := make([]string, 0, count)
+	unhealthy := make([]string, 0)
+	for i := 0; i < count; i++ {
+		ep := p.endpoints[(start+i)%count]
+		if ep.isHealthy(now) {
+			healthy = append(healthy, ep.url)
+		} else {
+			unhealthy = append(unhealthy, ep.url)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// pick returns the first endpoint url to try
+func (p *endpointPool) pick() string {
+	if urls := p.candidates(); len(urls) > 0 {
+		return urls[0]
+	}
+	return ""
+}
+
+// pickPrimary returns the first healthy endpoint url in configured order,
+// or the configured primary if all are unhealthy.
+func (p *endpointPool) pickPrimary() string {
+	if p == nil || len(p.endpoints) == 0 {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for _, ep := range p.endpoints {
+		if ep.isHealthy(now) {
+			return ep.url
+		}
+	}
+	return p.endpoints[0].url
+}
+
+// track updates the health of endpoint url according to the post result,
+// errors caused by the canceled ctx are not counted as failures.
+func (p *endpointPool) track(ctx context.Context, url string, err error) {
+	if p == nil {
+		return
+	}
+	switch {
+	case err == nil:
+		p.markSuccess(url)
+	case ctx.Err() == nil:
+		p.markFailure(url, err)
+	}
+}
+
+func (p *endpointPool) markSuccess(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range p.endpoints {
+		if ep.url == url {
+			if ep.failures > 0 {
+				log.Info("mpc rpc endpoint becomes healthy", "url", url)
+			}
+			ep.failures = 0
+			return
+		}
+	}
+}
+
+func (p *endpointPool) markFailure(url string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range p.endpoints {
+		if ep.url == url {
+			ep.failures++
+			ep.unhealthyUntil = time.Now().Add(p.unhealthyDuration)
+			log.Warn("mpc rpc endpoint is unhealthy", "url", url, "failures", ep.failures, "err", err)
+			return
+		}
+	}
+}
+
+// quorum returns the minimum agreement count of the endpoints
+func (p *endpointPool) quorum() int {
+	if p == nil {
+		return 1
+	}
+	return len(p.endpoints)/2 + 1
+}
@@ -0,0 +1,47 @@
+package mpcrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointPool(t *testing.T) {
+	_, err := newEndpointPool(nil, "", 0)
+	assert.Equal(t, errNoRPCEndpoint, err)
+	_, err = newEndpointPool([]string{"a"}, "unknown", 0)
+	assert.Error(t, err)
+
+	pool, err := newEndpointPool([]string{"a", "b", "a", " ", "c"}, "", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, StrategyFirstHealthy, pool.strategy)
+	assert.Equal(t, []string{"a", "b", "c"}, pool.urls())
+	assert.Equal(t, 2, pool.quorum())
+	assert.Equal(t, "a", pool.pick())
+
+	ctx := context.Background()
+	pool.track(ctx, "a", errors.New("connection refused"))
+	assert.Equal(t, []string{"b", "c", "a"}, pool.candidates())
+	pool.track(ctx, "a", nil)
+	assert.Equal(t, []string{"a", "b", "c"}, pool.candidates())
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	pool.track(canceledCtx, "a", canceledCtx.Err())
+	assert.Equal(t, "a", pool.pick(), "canceled call should not mark failure")
+}
+
+func TestEndpointPoolRoundRobin(t *testing.T) {
+	pool, err := newEndpointPool([]string{"a", "b", "c"}, StrategyRoundRobin, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, pool.candidates())
+	assert.Equal(t, []string{"b", "c", "a"}, pool.candidates())
+	// writes always go to the first healthy endpoint
+	assert.Equal(t, "a", pool.pickPrimary())
+	pool.track(context.Background(), "a", errors.New("timeout"))
+	assert.Equal(t, []string{"c", "b", "a"}, pool.candidates())
+	assert.Equal(t, "b", pool.pickPrimary())
+}
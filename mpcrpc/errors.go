@@ -0,0 +1,126 @@
+package mpcrpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errors classified from the mpc server answers,
+// check them with errors.Is(err, ErrXxx)
+var (
+	ErrNonceConflict     = errors.New("mpc nonce conflict")
+	ErrGroupNotFound     = errors.New("mpc group not found")
+	ErrThresholdMismatch = errors.New("mpc threshold mismatch")
+
+	// ErrVerifySignatureFailed the sign result is not a valid signature of
+	// the message hash signed by the sign public key
+	ErrVerifySignatureFailed = errors.New("mpc verify signature failed")
+)
+
+// the error messages or tips answered by smpc node of the classified errors,
+// they are matched case insensitively, unknown messages are not classified.
+var (
+	nonceConflictMessages = []string{
+		"check nonce fail",
+		"nonce is too low",
+		"nonce too low",
+		"nonce is already used",
+	}
+	groupNotFoundMessages = []string{
+		"group is not found",
+		"group not found",
+		"group is not exist",
+		"group not exist",
+		"get group info fail",
+	}
+	thresholdMismatchMessages = []string{
+		"threshold is not match",
+		"threshold mismatch",
+		"check threshold fail",
+	}
+)
+
+// StatusError the mpc server answers a status which is not success,
+// Message is the 'Error' field of the answer, it's not named Error
+// as it would conflict with the Error method.
+type StatusError struct {
+	Method  string // rpc method without api prefix, eg. getSignStatus
+	Status  string // status answered by server
+	Tip     string // tip answered by server
+	Message string // error message answered by server
+}
+
+// Error impl error interface
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("[%v] Wrong status \"%v\", err=\"%v\"", err.Method, err.Status, err.Message)
+}
+
+// Is classify the server answer to the sentinel errors
+func (err *StatusError) Is(target error) bool {
+	switch target {
+	case ErrGetSignStatusFailed:
+		return err.Method == "getSignStatus" && err.Status == "Failure"
+	case ErrGetSignStatusTimeout:
+		return err.Method == "getSignStatus" && err.Status == "Timeout"
+	case ErrGetDKGStatusFailed:
+		return err.Method == "getReqAddrStatus" && err.Status == "Failure"
+	case ErrGetDKGStatusTimeout:
+		return err.Method == "getReqAddrStatus" && err.Status == "Timeout"
+	case ErrNonceConflict:
+		return containsMessage(nonceConflictMessages, err.Message, err.Tip)
+	case ErrGroupNotFound:
+		return containsMessage(groupNotFoundMessages, err.Message, err.Tip)
+	case ErrThresholdMismatch:
+		return containsMessage(thresholdMismatchMessages, err.Message, err.Tip)
+	}
+	return false
+}
+
+// containsMessage reports whether any text contains any of the known messages
+func containsMessage(known []string, texts ...string) bool {
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, msg := range known {
+			if strings.Contains(text, msg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newStatusError(method, status, tip, errInfo string) *StatusError {
+	return &StatusError{
+		Method:  method,
+		Status:  status,
+		Tip:     tip,
+		Message: errInfo,
+	}
+}
+
+// wrappedError wraps the cause error with a kind error,
+// both of them can be checked by errors.Is and errors.As.
+type wrappedError struct {
+	kind  error
+	cause error
+}
+
+func wrapError(kind, cause error) error {
+	return &wrappedError{kind: kind, cause: cause}
+}
+
+// Error impl error interface
+func (err *wrappedError) Error() string {
+	return err.kind.Error() + ", " + err.cause.Error()
+}
+
+// Unwrap returns the cause error
+func (err *wrappedError) Unwrap() error {
+	return err.cause
+}
+
+// Is reports whether target is the kind error
+func (err *wrappedError) Is(target error) bool {
+	return target == err.kind
+}
@@ -0,0 +1,51 @@
+package mpcrpc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/anyswap/mpc-client/mpcrpc/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusError(t *testing.T) {
+	var err error = newStatusError("getSignStatus", "Failure", "", "sign failed")
+	assert.True(t, errors.Is(err, ErrGetSignStatusFailed))
+	assert.False(t, errors.Is(err, ErrGetSignStatusTimeout))
+	assert.False(t, errors.Is(err, ErrGetDKGStatusFailed))
+
+	err = newStatusError("sign", "Error", "", "check Nonce fail, nonce is too low")
+	assert.True(t, errors.Is(err, ErrNonceConflict))
+	assert.False(t, errors.Is(err, ErrGroupNotFound))
+
+	err = newStatusError("sign", "Error", "group is not found", "")
+	assert.True(t, errors.Is(err, ErrGroupNotFound))
+
+	err = wrapError(errDoSignFailed, wrapError(errGetSignResultFailed, err))
+	assert.True(t, errors.Is(err, errDoSignFailed))
+	assert.True(t, errors.Is(err, errGetSignResultFailed))
+	assert.True(t, errors.Is(err, ErrGroupNotFound))
+	var statusErr *StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, "sign", statusErr.Method)
+
+	err = wrapError(errDoSignFailed, fmt.Errorf("[post] sign error, %w", &client.TransportError{URL: "http://127.0.0.1:1234", Err: errors.New("connection refused")}))
+	var transportErr *client.TransportError
+	assert.True(t, errors.As(err, &transportErr))
+	assert.Equal(t, "http://127.0.0.1:1234", transportErr.URL)
+
+	err = newStatusError("sign", "Error", "", "threshold is not match with group")
+	assert.True(t, errors.Is(err, ErrThresholdMismatch))
+
+	// unknown messages are not classified
+	for _, msg := range []string{"invalid nonce format", "threshold parameter missing", "group id is wrong", "unknown error"} {
+		err = newStatusError("sign", "Error", msg, msg)
+		assert.False(t, errors.Is(err, ErrNonceConflict), msg)
+		assert.False(t, errors.Is(err, ErrThresholdMismatch), msg)
+		assert.False(t, errors.Is(err, ErrGroupNotFound), msg)
+		assert.False(t, isNonceConflict(err), msg)
+	}
+	assert.False(t, isNonceConflict(&client.JSONRPCError{Code: -32000, Message: "invalid nonce format"}))
+	assert.True(t, isNonceConflict(&client.JSONRPCError{Code: -32000, Message: "nonce too low"}))
+}
@@ -0,0 +1,199 @@
+package mpcrpc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mpc key types (sign algorithms)
+const (
+	KeyTypeECDSA   = "ECDSA"
+	KeyTypeED25519 = "ED25519"
+)
+
+var (
+	errUnknownKeyType         = errors.New("unknown mpc key type")
+	errWrongPublicKey         = errors.New("wrong mpc public key")
+	errED25519VerifySignature = errors.New("verify ed25519 signature failed")
+	errWrongECDSAMsgHash      = errors.New("ecdsa message hash must be 32 bytes")
+	errRsvCountMismatch       = errors.New("rsv count mismatch with message hash count")
+)
+
+// NormalizeKeyType returns the normalized key type,
+// eg. 'ed25519' and 'EDDSA' are normalized to 'ED25519'.
+func NormalizeKeyType(keyType string) (string, error) {
+	switch strings.ToUpper(keyType) {
+	case KeyTypeECDSA:
+		return KeyTypeECDSA, nil
+	case KeyTypeED25519, "EDDSA":
+		return KeyTypeED25519, nil
+	default:
+		return "", fmt.Errorf("%w '%v'", errUnknownKeyType, keyType)
+	}
+}
+
+// SignatureLength returns the length of signature of key type,
+// ECDSA is 65 bytes (r, s, v), ED25519 is 64 bytes (R, S).
+func SignatureLength(keyType string) int {
+	if keyType == KeyTypeED25519 {
+		return ed25519.SignatureSize
+	}
+	return crypto.SignatureLength
+}
+
+// CheckPublicKey checks the format of public key of key type,
+// ECDSA is 65 bytes uncompressed key, ED25519 is 32 bytes key.
+func CheckPublicKey(keyType, pubkey string) error {
+	pkBytes := common.FromHex(pubkey)
+	switch keyType {
+	case KeyTypeECDSA:
+		if len(pkBytes) == 65 && pkBytes[0] == 4 {
+			return nil
+		}
+	case KeyTypeED25519:
+		if len(pkBytes) == ed25519.PublicKeySize {
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w '%v'", errUnknownKeyType, keyType)
+	}
+	return fmt.Errorf("%w '%v' of key type %v", errWrongPublicKey, pubkey, keyType)
+}
+
+// VerifyED25519Signature verify ed25519 signature of message
+func VerifyED25519Signature(pubkey, message, signature string) error {
+	pkBytes := common.FromHex(pubkey)
+	if len(pkBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w '%v' of key type %v", errWrongPublicKey, pubkey, KeyTypeED25519)
+	}
+	sigBytes := common.FromHex(signature)
+	if len(sigBytes) != ed25519.SignatureSize {
+		return errWrongSignatureLength
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pkBytes), common.FromHex(message), sigBytes) {
+		return fmt.Errorf("%w, %v", ErrVerifySignatureFailed, errED25519VerifySignature)
+	}
+	return nil
+}
+
+// VerifySignature verify signature of msgHash is signed by pubkey,
+// ECDSA signature is verified by ecrecover, ED25519 is by ed25519 verify.
+func VerifySignature(keyType, pubkey, msgHash, signature string) error {
+	switch keyType {
+	case KeyTypeECDSA:
+		return verifyECDSASignature(pubkey, msgHash, signature)
+	case KeyTypeED25519:
+		return VerifyED25519Signature(pubkey, msgHash, signature)
+	default:
+		return fmt.Errorf("%w '%v'", errUnknownKeyType, keyType)
+	}
+}
+
+func verifyECDSASignature(pubkey, msgHash, signature string) error {
+	hash := common.FromHex(msgHash)
+	if len(hash) != common.HashLength {
+		return errWrongECDSAMsgHash
+	}
+	sigBytes := common.FromHex(signature)
+	if len(sigBytes) != crypto.SignatureLength {
+		return errWrongSignatureLength
+	}
+	recovered, err := crypto.Ecrecover(hash, sigBytes)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrVerifySignatureFailed, err)
+	}
+	if !bytes.Equal(recovered, common.FromHex(pubkey)) {
+		return fmt.Errorf("%w, recovered pubkey is %v", ErrVerifySignatureFailed, hexutil.Encode(recovered))
+	}
+	return nil
+}
+
+// verifySignatures verify every rsv is the signature of
+// the corresponding msgHash signed by signPubkey.
+func verifySignatures(keyType, signPubkey string, msgHashes, rsvs []string) error {
+	if len(rsvs) != len(msgHashes) {
+		return fmt.Errorf("%w, rsvs %v, msgHashes %v", errRsvCountMismatch, len(rsvs), len(msgHashes))
+	}
+	for i, rsv := range rsvs {
+		if err := VerifySignature(keyType, signPubkey, msgHashes[i], rsv); err != nil {
+			return fmt.Errorf("verify signature of msgHash %v failed, %w", msgHashes[i], err)
+		}
+	}
+	return nil
+}
+
+// MatchSignatures returns the rsvs in the order of msgHashes,
+// every rsv is assigned to the msgHash it's verified to sign,
+// it fails if any msgHash has no signature.
+func MatchSignatures(keyType, signPubkey string, msgHashes, rsvs []string) ([]string, error) {
+	err := verifySignatures(keyType, signPubkey, msgHashes, rsvs)
+	if err == nil || len(rsvs) != len(msgHashes) {
+		return rsvs, err
+	}
+	matched := make([]string, len(msgHashes))
+	used := make([]bool, len(rsvs))
+	for i, msgHash := range msgHashes {
+		for j, rsv := range rsvs {
+			if !used[j] && VerifySignature(keyType, signPubkey, msgHash, rsv) == nil {
+				matched[i] = rsv
+				used[j] = true
+				break
+			}
+		}
+		if matched[i] == "" {
+			return nil, fmt.Errorf("%w, no signature of msgHash %v", ErrVerifySignatureFailed, msgHash)
+		}
+	}
+	return matched, nil
+}
+
+// PublicKeyInfo information derived from mpc public key
+type PublicKeyInfo struct {
+	KeyType       string
+	PublicKey     string
+	CompressedKey string `json:",omitempty"`
+	PubKeyHash    string `json:",omitempty"` // hash160 of compressed key, used by bitcoin-family addresses
+	EVMAddress    string `json:",omitempty"`
+	Base58Address string `json:",omitempty"` // solana-style address of ed25519 key
+}
+
+// GetPublicKeyInfo derive the compressed key and addresses from public key,
+// ECDSA key has compressed key, pubkey hash and EVM address,
+// ED25519 key has base58 address.
+func GetPublicKeyInfo(keyType, pubkey string) (*PublicKeyInfo, error) {
+	keyType, err := NormalizeKeyType(keyType)
+	if err != nil {
+		return nil, err
+	}
+	if err = CheckPublicKey(keyType, pubkey); err != nil {
+		return nil, err
+	}
+	pkBytes := common.FromHex(pubkey)
+	info := &PublicKeyInfo{
+		KeyType:   keyType,
+		PublicKey: hexutil.Encode(pkBytes),
+	}
+	switch keyType {
+	case KeyTypeECDSA:
+		pk, err := crypto.UnmarshalPubkey(pkBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%v', %v", errWrongPublicKey, pubkey, err)
+		}
+		compressed := crypto.CompressPubkey(pk)
+		info.CompressedKey = hexutil.Encode(compressed)
+		info.PubKeyHash = hexutil.Encode(btcutil.Hash160(compressed))
+		info.EVMAddress = crypto.PubkeyToAddress(*pk).String()
+	case KeyTypeED25519:
+		info.Base58Address = base58.Encode(pkBytes)
+	}
+	return info, nil
+}
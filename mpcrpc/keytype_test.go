@@ -0,0 +1,92 @@
+package mpcrpc
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyType(t *testing.T) {
+	keyType, err := NormalizeKeyType("eddsa")
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeED25519, keyType)
+	keyType, err = NormalizeKeyType("ecdsa")
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeECDSA, keyType)
+	_, err = NormalizeKeyType("rsa")
+	assert.Error(t, err)
+
+	assert.Equal(t, 65, SignatureLength(KeyTypeECDSA))
+	assert.Equal(t, 64, SignatureLength(KeyTypeED25519))
+
+	ecKey, _ := crypto.GenerateKey()
+	ecPubkey := hexutil.Encode(crypto.FromECDSAPub(&ecKey.PublicKey))
+	edPubkey, edPrivKey, _ := ed25519.GenerateKey(nil)
+
+	assert.NoError(t, CheckPublicKey(KeyTypeECDSA, ecPubkey))
+	assert.Error(t, CheckPublicKey(KeyTypeED25519, ecPubkey))
+	assert.NoError(t, CheckPublicKey(KeyTypeED25519, hexutil.Encode(edPubkey)))
+	assert.Error(t, CheckPublicKey(KeyTypeECDSA, hexutil.Encode(edPubkey)))
+
+	message := []byte("message to sign")
+	signature := ed25519.Sign(edPrivKey, message)
+	assert.NoError(t, VerifyED25519Signature(hexutil.Encode(edPubkey), hexutil.Encode(message), hexutil.Encode(signature)))
+	signature[0] ^= 1
+	err = VerifyED25519Signature(hexutil.Encode(edPubkey), hexutil.Encode(message), hexutil.Encode(signature))
+	assert.True(t, errors.Is(err, ErrVerifySignatureFailed))
+}
+
+func TestVerifySignatures(t *testing.T) {
+	ecKey, _ := crypto.GenerateKey()
+	ecPubkey := hexutil.Encode(crypto.FromECDSAPub(&ecKey.PublicKey))
+	otherKey, _ := crypto.GenerateKey()
+
+	msgHashes := []string{
+		crypto.Keccak256Hash([]byte("message 1")).String(),
+		crypto.Keccak256Hash([]byte("message 2")).String(),
+	}
+	rsvs := make([]string, len(msgHashes))
+	for i, msgHash := range msgHashes {
+		sig, _ := crypto.Sign(common.FromHex(msgHash), ecKey)
+		rsvs[i] = hexutil.Encode(sig)
+	}
+	assert.NoError(t, verifySignatures(KeyTypeECDSA, ecPubkey, msgHashes, rsvs))
+	assert.Error(t, verifySignatures(KeyTypeECDSA, ecPubkey, msgHashes, rsvs[:1]))
+	assert.Error(t, verifySignatures(KeyTypeECDSA, ecPubkey, msgHashes, []string{rsvs[1], rsvs[0]}))
+
+	badSig, _ := crypto.Sign(common.FromHex(msgHashes[1]), otherKey)
+	err := verifySignatures(KeyTypeECDSA, ecPubkey, msgHashes, []string{rsvs[0], hexutil.Encode(badSig)})
+	assert.True(t, errors.Is(err, ErrVerifySignatureFailed))
+
+	// rsvs are assigned to the hashes they sign
+	matched, err := MatchSignatures(KeyTypeECDSA, ecPubkey, msgHashes, []string{rsvs[1], rsvs[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, rsvs, matched)
+	_, err = MatchSignatures(KeyTypeECDSA, ecPubkey, msgHashes, []string{rsvs[1], rsvs[1]})
+	assert.True(t, errors.Is(err, ErrVerifySignatureFailed))
+	_, err = MatchSignatures(KeyTypeECDSA, ecPubkey, msgHashes, []string{rsvs[0], hexutil.Encode(badSig)})
+	assert.True(t, errors.Is(err, ErrVerifySignatureFailed))
+}
+
+func TestGetPublicKeyInfo(t *testing.T) {
+	// public key of private key 1
+	pubkey := "0x0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+	info, err := GetPublicKeyInfo("ecdsa", pubkey)
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeECDSA, info.KeyType)
+	assert.Equal(t, "0x0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", info.CompressedKey)
+	assert.Equal(t, "0x751e76e8199196d454941c45d1b3a323f1433bd6", info.PubKeyHash)
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", info.EVMAddress)
+
+	info, err = GetPublicKeyInfo("ed25519", hexutil.Encode(make([]byte, 32)))
+	assert.NoError(t, err)
+	assert.Equal(t, "11111111111111111111111111111111", info.Base58Address)
+
+	_, err = GetPublicKeyInfo("ecdsa", "0x04"+common.Bytes2Hex(make([]byte, 64)))
+	assert.Error(t, err)
+}
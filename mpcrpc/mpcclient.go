@@ -0,0 +1,205 @@
+package mpcrpc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anyswap/mpc-client/internal/tools"
+	"github.com/anyswap/mpc-client/log"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	errEmptyRPCAddress       = errors.New("must specify mpc rpc url")
+	errEmptyGroupOrThreshold = errors.New("must specify sign group and threshold")
+	errKeyStoreNotLoaded     = errors.New("mpc user keystore is not loaded")
+)
+
+// Client mpc client, which owns its rpc address, keystore, sign group,
+// threshold and timeouts. Different clients can talk to different
+// mpc clusters or sign groups in one process.
+type Client struct {
+	apiPrefix  string
+	rpcAddress string
+	rpcTimeout int
+	endpoints  *endpointPool
+	rpcRetry   *RetryPolicy
+
+	keyWrapper   *keystore.Key
+	user         common.Address
+	signNonce    *nonceManager
+	reqAddrNonce *nonceManager
+
+	signType    string
+	signGroup   string
+	threshold   string
+	mode        string
+	signTimeout time.Duration
+	signPoll    *RetryPolicy
+	dkgPoll     *RetryPolicy
+	signPoller  *signPoller
+}
+
+func newDefaultClient() *Client {
+	c := &Client{
+		apiPrefix:   defaultAPIPrefix,
+		rpcTimeout:  defaultRPCTimeout,
+		rpcRetry:    defaultRPCRetryPolicy(),
+		signType:    defaultSignType,
+		signTimeout: defaultSignTimeout,
+		signPoll:    newPollPolicy(defaultSignPollInterval, 0),
+		dkgPoll:     newPollPolicy(defaultDKGPollInterval, 0),
+	}
+	c.signPoller = newSignPoller(c)
+	c.initNonceManagers()
+	return c
+}
+
+// NewClient new mpc client from config
+func NewClient(mpcConfig *MPCConfig, isSign bool) (*Client, error) {
+	c := newDefaultClient()
+	if err := c.initRPC(mpcConfig); err != nil {
+		return nil, fmt.Errorf("init mpc rpc failed, %w", err)
+	}
+	if isSign || mpcConfig.SignGroup != "" {
+		if err := c.initSign(mpcConfig); err != nil {
+			return nil, fmt.Errorf("init mpc sign failed, %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Client) initRPC(mpcConfig *MPCConfig) error {
+	if mpcConfig.APIPrefix != "" {
+		c.apiPrefix = mpcConfig.APIPrefix
+	}
+	if mpcConfig.RPCTimeout > 0 {
+		c.rpcTimeout = int(mpcConfig.RPCTimeout)
+	}
+	if mpcConfig.RPCRetryTimes != nil {
+		c.rpcRetry.MaxAttempts = int(*mpcConfig.RPCRetryTimes) + 1
+	}
+	if mpcConfig.RPCRetryBackoff > 0 {
+		c.rpcRetry.InitialBackoff = time.Duration(mpcConfig.RPCRetryBackoff) * time.Millisecond
+	}
+
+	c.rpcAddress = mpcConfig.RPCAddress
+	if c.rpcAddress == "" && len(mpcConfig.RPCAddresses) > 0 {
+		c.rpcAddress = mpcConfig.RPCAddresses[0]
+	}
+	if c.rpcAddress == "" {
+		return errEmptyRPCAddress
+	}
+	urls := append([]string{c.rpcAddress}, mpcConfig.RPCAddresses...)
+	unhealthyDuration := time.Duration(mpcConfig.RPCUnhealthyDuration) * time.Second
+	endpoints, err := newEndpointPool(urls, mpcConfig.RPCStrategy, unhealthyDuration)
+	if err != nil {
+		return err
+	}
+	c.endpoints = endpoints
+
+	if mpcConfig.NeedKeyStore || mpcConfig.KeystoreFile != "" {
+		key, err := tools.LoadKeyStore(mpcConfig.KeystoreFile, mpcConfig.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("load mpc user keystore failed, %w", err)
+		}
+		c.keyWrapper = key
+		c.user = key.Address
+		log.Info("load mpc user keystore success", "mpcUser", c.user.String())
+	}
+
+	log.Info("init mpc rpc success", "apiPrefix", c.apiPrefix, "rpcAddresses", c.endpoints.urls(), "strategy", c.endpoints.strategy, "rpcTimeout", c.rpcTimeout, "rpcRetryTimes", c.rpcRetry.MaxAttempts-1)
+	return nil
+}
+
+func (c *Client) initSign(mpcConfig *MPCConfig) error {
+	if mpcConfig.SignTimeout > 0 {
+		c.signTimeout = time.Duration(mpcConfig.SignTimeout * uint64(time.Second))
+	}
+	if mpcConfig.SignType != "" {
+		signType, err := NormalizeKeyType(mpcConfig.SignType)
+		if err != nil {
+			return err
+		}
+		c.signType = signType
+	}
+	maxPollInterval := time.Duration(mpcConfig.PollMaxInterval) * time.Second
+	if mpcConfig.SignPollInterval > 0 {
+		c.signPoll = newPollPolicy(time.Duration(mpcConfig.SignPollInterval)*time.Second, maxPollInterval)
+	}
+	if mpcConfig.DKGPollInterval > 0 {
+		c.dkgPoll = newPollPolicy(time.Duration(mpcConfig.DKGPollInterval)*time.Second, maxPollInterval)
+	}
+	c.signGroup = mpcConfig.SignGroup
+	c.threshold = mpcConfig.Threshold
+	if mpcConfig.Mode != nil {
+		c.mode = fmt.Sprintf("%d", *mpcConfig.Mode)
+	} else {
+		c.mode = "0"
+	}
+
+	if c.signGroup == "" || c.threshold == "" {
+		return errEmptyGroupOrThreshold
+	}
+
+	log.Info("init mpc sign success", "signType", c.signType, "signGroup", c.signGroup, "threshold", c.threshold, "mode", c.mode, "signTimeout", c.signTimeout.String())
+	return nil
+}
+
+// RPCAddress returns the primary mpc rpc address
+func (c *Client) RPCAddress() string {
+	return c.rpcAddress
+}
+
+// RPCAddresses returns all the mpc rpc addresses
+func (c *Client) RPCAddresses() []string {
+	return c.endpoints.urls()
+}
+
+// User returns the mpc user address of the loaded keystore
+func (c *Client) User() common.Address {
+	return c.user
+}
+
+// SignType returns the mpc sign type, eg. ECDSA, ED25519
+func (c *Client) SignType() string {
+	return c.signType
+}
+
+// SignGroup returns the mpc sign group ID
+func (c *Client) SignGroup() string {
+	return c.signGroup
+}
+
+// Threshold returns the mpc sign threshold
+func (c *Client) Threshold() string {
+	return c.threshold
+}
+
+// SignWithKey sign by mpc node user with private key
+func (c *Client) SignWithKey(message []byte) ([]byte, error) {
+	if c.keyWrapper == nil {
+		return nil, errKeyStoreNotLoaded
+	}
+	return crypto.Sign(message, c.keyWrapper.PrivateKey)
+}
+
+// SignContent sign content
+func (c *Client) SignContent(content []byte) (signature []byte, err error) {
+	return c.SignWithKey(crypto.Keccak256(content))
+}
+
+// use the first healthy rpc address of the client if rpcAddr is empty,
+// it's used by write methods, which are sent to the primary regardless of strategy.
+func (c *Client) getRPCAddress(rpcAddr string) string {
+	if rpcAddr != "" {
+		return rpcAddr
+	}
+	if url := c.endpoints.pickPrimary(); url != "" {
+		return url
+	}
+	return c.rpcAddress
+}
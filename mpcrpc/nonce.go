@@ -0,0 +1,97 @@
+package mpcrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/anyswap/mpc-client/mpcrpc/client"
+)
+
+const maxNonceSubmitTimes = 3
+
+// nonceManager allocates the nonces of mpc user for sign or dkg requests.
+// It fetches the nonce from mpc server once and then increments locally,
+// and resyncs from mpc server if the nonce is rejected as conflict
+// or the request is sent to another endpoint than the synced one.
+// Requests are sent in nonce order as the lock is held while sending.
+type nonceManager struct {
+	name  string
+	fetch func(ctx context.Context, rpcAddr string) (uint64, error)
+
+	mu       sync.Mutex
+	next     uint64
+	synced   bool
+	endpoint string
+}
+
+func newNonceManager(name string, fetch func(ctx context.Context, rpcAddr string) (uint64, error)) *nonceManager {
+	return &nonceManager{
+		name:  name,
+		fetch: fetch,
+	}
+}
+
+// submit calls send with an allocated nonce fetched from rpcAddr, and retries
+// with the resynced nonce if send fails because of nonce conflict.
+func (m *nonceManager) submit(ctx context.Context, rpcAddr string, send func(nonce uint64) error) (err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 0; i < maxNonceSubmitTimes; i++ {
+		if !m.synced || m.endpoint != rpcAddr {
+			nonce, errf := m.fetch(ctx, rpcAddr)
+			if errf != nil {
+				return errf
+			}
+			m.next = nonce
+			m.synced = true
+			m.endpoint = rpcAddr
+		}
+		nonce := m.next
+		err = send(nonce)
+		if err == nil {
+			m.next++
+			return nil
+		}
+		// the request may be received by mpc server or not, resync next time
+		m.synced = false
+		if !isNonceConflict(err) || ctx.Err() != nil {
+			return err
+		}
+		log.Warn("mpc nonce conflict, resync nonce", "type", m.name, "nonce", nonce, "err", err)
+	}
+	return err
+}
+
+// reset makes the next submit resync the nonce from mpc server
+func (m *nonceManager) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.synced = false
+}
+
+func isNonceConflict(err error) bool {
+	if errors.Is(err, ErrNonceConflict) {
+		return true
+	}
+	var rpcErr *client.JSONRPCError
+	return errors.As(err, &rpcErr) && containsMessage(nonceConflictMessages, rpcErr.Message)
+}
+
+func (c *Client) initNonceManagers() {
+	c.signNonce = newNonceManager("sign", func(ctx context.Context, rpcAddr string) (uint64, error) {
+		return c.GetSignNonceContext(ctx, c.user.String(), rpcAddr)
+	})
+	c.reqAddrNonce = newNonceManager("reqAddr", func(ctx context.Context, rpcAddr string) (uint64, error) {
+		return c.GetReqAddrNonceContext(ctx, c.user.String(), rpcAddr)
+	})
+}
+
+// ResetNonce makes the next sign and dkg requests resync
+// the nonce from mpc server, it's useful if the mpc user is
+// used by other processes at the same time.
+func (c *Client) ResetNonce() {
+	c.signNonce.reset()
+	c.reqAddrNonce.reset()
+}
@@ -0,0 +1,64 @@
+package mpcrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonceManager(t *testing.T) {
+	var mu sync.Mutex
+	serverNonce := uint64(5)
+	fetches := 0
+	fetch := func(ctx context.Context, rpcAddr string) (uint64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		return serverNonce, nil
+	}
+	send := func(nonce uint64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if nonce != serverNonce {
+			return newStatusError("sign", "Error", "", "check Nonce fail")
+		}
+		serverNonce++
+		return nil
+	}
+
+	m := newNonceManager("sign", fetch)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, m.submit(ctx, "a", send))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(25), serverNonce)
+	assert.Equal(t, 1, fetches)
+
+	// nonce is used by others, resync on conflict
+	serverNonce += 3
+	assert.NoError(t, m.submit(ctx, "a", send))
+	assert.Equal(t, uint64(29), serverNonce)
+	assert.Equal(t, 2, fetches)
+
+	// other errors are returned without retry
+	errSend := errors.New("connection refused")
+	err := m.submit(ctx, "a", func(nonce uint64) error { return errSend })
+	assert.Equal(t, errSend, err)
+	assert.Equal(t, 2, fetches)
+	assert.NoError(t, m.submit(ctx, "a", send))
+	assert.Equal(t, 3, fetches)
+
+	// resync if sending to another endpoint
+	assert.NoError(t, m.submit(ctx, "b", send))
+	assert.Equal(t, 4, fetches)
+}
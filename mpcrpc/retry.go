@@ -0,0 +1,88 @@
+package mpcrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/anyswap/mpc-client/mpcrpc/client"
+)
+
+// RetryPolicy retry policy of rpc calls and status polling
+type RetryPolicy = client.RetryPolicy
+
+const (
+	defaultRPCRetryTimes    = 2
+	defaultRPCRetryBackoff  = 500 * time.Millisecond
+	defaultRPCRetryJitter   = 0.2
+	defaultSignPollInterval = 3 * time.Second
+	defaultDKGPollInterval  = 1 * time.Second
+)
+
+type rpcRetryPolicyKey struct{}
+
+type pollPolicyKey struct{}
+
+// WithRetryPolicy returns a copy of ctx, the calls using it
+// retry the failed rpc post with the specified policy.
+func WithRetryPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	return context.WithValue(ctx, rpcRetryPolicyKey{}, policy)
+}
+
+// WithPollPolicy returns a copy of ctx, the calls using it poll
+// the sign or dkg status with the specified policy.
+// The policy's Retryable is not used as the status decides whether to continue.
+func WithPollPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	return context.WithValue(ctx, pollPolicyKey{}, policy)
+}
+
+func defaultRPCRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    defaultRPCRetryTimes + 1,
+		InitialBackoff: defaultRPCRetryBackoff,
+		Jitter:         defaultRPCRetryJitter,
+	}
+}
+
+// newPollPolicy polls in constant interval, or in exponential growing
+// interval if maxInterval is greater than interval.
+func newPollPolicy(interval, maxInterval time.Duration) *RetryPolicy {
+	policy := &RetryPolicy{
+		InitialBackoff: interval,
+		Multiplier:     1,
+	}
+	if maxInterval > interval {
+		policy.Multiplier = 0 // use default multiplier
+		policy.MaxBackoff = maxInterval
+	}
+	return policy
+}
+
+// rpc methods which change state in mpc server, they are retried
+// only if the request is not sent out for safety.
+var writeMethods = map[string]bool{
+	"sign":          true,
+	"acceptSign":    true,
+	"reqDcrmAddr":   true,
+	"acceptReqAddr": true,
+}
+
+func (c *Client) getRPCRetryPolicy(ctx context.Context, method string) *RetryPolicy {
+	policy, ok := ctx.Value(rpcRetryPolicyKey{}).(*RetryPolicy)
+	if !ok || policy == nil {
+		policy = c.rpcRetry
+	}
+	if writeMethods[method] {
+		policy = policy.WithRetryable(client.IsDialError)
+	}
+	return policy
+}
+
+func (c *Client) getPollPolicy(ctx context.Context, isDKG bool) *RetryPolicy {
+	if policy, ok := ctx.Value(pollPolicyKey{}).(*RetryPolicy); ok && policy != nil {
+		return policy
+	}
+	if isDKG {
+		return c.dkgPoll
+	}
+	return c.signPoll
+}
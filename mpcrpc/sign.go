@@ -1,6 +1,7 @@
 package mpcrpc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"math/big"
@@ -10,7 +11,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -19,97 +19,123 @@ var (
 	errDoSignFailed         = errors.New("do sign failed")
 	errSignWithoutPublickey = errors.New("sign without public key")
 	errGetSignResultFailed  = errors.New("get sign result failed")
+	errEmptySignResult      = errors.New("empty sign result")
 	errWrongSignatureLength = errors.New("wrong signature length")
 )
 
-// SignContent sign content
-func SignContent(content []byte) (signature []byte, err error) {
-	return crypto.Sign(crypto.Keccak256(content), mpcKeyWrapper.PrivateKey)
-}
-
 // DoSignOne mpc sign single msgHash with context msgContext
-func DoSignOne(signPubkey, msgHash, msgContext string) (keyID string, rsvs []string, err error) {
-	return DoSign(signPubkey, []string{msgHash}, []string{msgContext})
+func (c *Client) DoSignOne(signPubkey, msgHash, msgContext string) (keyID string, rsvs []string, err error) {
+	return c.DoSign(signPubkey, []string{msgHash}, []string{msgContext})
 }
 
 // DoSign mpc sign msgHash with context msgContext
-func DoSign(signPubkey string, msgHash, msgContext []string) (keyID string, rsvs []string, err error) {
+func (c *Client) DoSign(signPubkey string, msgHash, msgContext []string) (keyID string, rsvs []string, err error) {
+	return c.DoSignContext(context.Background(), signPubkey, msgHash, msgContext)
+}
+
+// DoSignContext mpc sign msgHash with context msgContext,
+// the sign is aborted if ctx is canceled before it is finished.
+// The returned rsvs are verified to be signed by signPubkey.
+func (c *Client) DoSignContext(ctx context.Context, signPubkey string, msgHash, msgContext []string) (keyID string, rsvs []string, err error) {
 	log.Info("mpc DoSign", "msgHash", msgHash, "msgContext", msgContext)
 	if signPubkey == "" {
 		return "", nil, errSignWithoutPublickey
 	}
-	keyID, rsvs, err = doSignImpl(signPubkey, msgHash, msgContext)
+	keyID, rsvs, err = c.doSignImpl(ctx, signPubkey, msgHash, msgContext)
 	if err != nil {
 		log.Error("mpc DoSign failed", "err", err)
-		return "", nil, errDoSignFailed
+		return "", nil, wrapError(errDoSignFailed, err)
 	}
 	log.Info("mpc DoSign success")
 	return keyID, rsvs, nil
 }
 
-func doSignImpl(signPubkey string, msgHash, msgContext []string) (keyID string, rsvs []string, err error) {
-	nonce, err := GetSignNonce(mpcUser.String(), mpcRPCAddress)
+func (c *Client) doSignImpl(ctx context.Context, signPubkey string, msgHash, msgContext []string) (keyID string, rsvs []string, err error) {
+	rpcAddr := "" // empty rpc address means using the healthy endpoints
+	keyID, err = c.submitSign(ctx, signPubkey, msgHash, msgContext, rpcAddr)
 	if err != nil {
 		return "", nil, err
 	}
+
+	rsvs, err = c.getSignResult(ctx, keyID, rpcAddr)
+	if err != nil {
+		return "", nil, err
+	}
+	rsvs, err = MatchSignatures(c.signType, signPubkey, msgHash, rsvs)
+	if err != nil {
+		return "", nil, err
+	}
+	return keyID, rsvs, nil
+}
+
+func (c *Client) submitSign(ctx context.Context, signPubkey string, msgHash, msgContext []string, rpcAddr string) (keyID string, err error) {
 	txdata := SignData{
 		TxType:     "SIGN",
 		PubKey:     signPubkey,
 		MsgHash:    msgHash,
 		MsgContext: msgContext,
-		Keytype:    mpcSignType,
-		GroupID:    mpcSignGroup,
-		ThresHold:  mpcThreshold,
-		Mode:       mpcMode,
+		Keytype:    c.signType,
+		GroupID:    c.signGroup,
+		ThresHold:  c.threshold,
+		Mode:       c.mode,
 		TimeStamp:  NowMilliStr(),
 	}
 	payload, _ := json.Marshal(txdata)
-	rawTX, err := BuildMPCRawTx(nonce, payload)
-	if err != nil {
-		return "", nil, err
-	}
-
-	rpcAddr := mpcRPCAddress
-	keyID, err = Sign(rawTX, rpcAddr)
-	if err != nil {
-		return "", nil, err
-	}
+	// the nonce is fetched from the same endpoint the sign is sent to
+	rpcAddr = c.getRPCAddress(rpcAddr)
+	err = c.signNonce.submit(ctx, rpcAddr, func(nonce uint64) error {
+		rawTX, errf := c.BuildMPCRawTx(nonce, payload)
+		if errf != nil {
+			return errf
+		}
+		keyID, errf = c.SignContext(ctx, rawTX, rpcAddr)
+		return errf
+	})
+	return keyID, err
+}
 
-	rsvs, err = getSignResult(keyID, rpcAddr)
-	if err != nil {
-		return "", nil, err
-	}
+func checkSignatures(keyType string, rsvs []string) error {
+	sigLength := SignatureLength(keyType)
 	for _, rsv := range rsvs {
 		signature := common.FromHex(rsv)
-		if len(signature) != crypto.SignatureLength {
-			return "", nil, errWrongSignatureLength
+		if len(signature) != sigLength {
+			return errWrongSignatureLength
 		}
 	}
-	return keyID, rsvs, nil
+	return nil
 }
 
 // GetSignStatusByKeyID get sign status by keyID
-func GetSignStatusByKeyID(keyID string) (rsvs []string, err error) {
-	return getSignResult(keyID, mpcRPCAddress)
+func (c *Client) GetSignStatusByKeyID(keyID string) (rsvs []string, err error) {
+	return c.GetSignStatusByKeyIDContext(context.Background(), keyID)
+}
+
+// GetSignStatusByKeyIDContext get sign status by keyID with context
+func (c *Client) GetSignStatusByKeyIDContext(ctx context.Context, keyID string) (rsvs []string, err error) {
+	return c.getSignResult(ctx, keyID, "")
 }
 
-func getSignResult(keyID, rpcAddr string) (rsvs []string, err error) {
+func (c *Client) getSignResult(ctx context.Context, keyID, rpcAddr string) (rsvs []string, err error) {
 	log.Info("start get sign status", "keyID", keyID)
 	var signStatus *SignStatus
 	i := 0
-	signTimer := time.NewTimer(mpcSignTimeout)
+	pollPolicy := c.getPollPolicy(ctx, false)
+	signTimer := time.NewTimer(c.signTimeout)
 	defer signTimer.Stop()
 LOOP_GET_SIGN_STATUS:
 	for {
 		i++
 		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break LOOP_GET_SIGN_STATUS
 		case <-signTimer.C:
 			if err == nil {
 				err = errSignTimerTimeout
 			}
 			break LOOP_GET_SIGN_STATUS
 		default:
-			signStatus, err = GetSignStatus(keyID, rpcAddr)
+			signStatus, err = c.GetSignStatusContext(ctx, keyID, rpcAddr)
 			if err == nil {
 				rsvs = signStatus.Rsv
 				break LOOP_GET_SIGN_STATUS
@@ -120,18 +146,24 @@ LOOP_GET_SIGN_STATUS:
 				break LOOP_GET_SIGN_STATUS
 			}
 		}
-		time.Sleep(3 * time.Second)
+		if !pollPolicy.CanAttempt(i + 1) {
+			break LOOP_GET_SIGN_STATUS
+		}
+		sleepContext(ctx, pollPolicy.Backoff(i))
 	}
-	if len(rsvs) == 0 || err != nil {
+	if err == nil && len(rsvs) == 0 {
+		err = errEmptySignResult
+	}
+	if err != nil {
 		log.Info("get sign status failed", "keyID", keyID, "retryCount", i, "err", err)
-		return nil, errGetSignResultFailed
+		return nil, wrapError(errGetSignResultFailed, err)
 	}
 	log.Info("get sign status success", "keyID", keyID, "retryCount", i)
 	return rsvs, nil
 }
 
 // BuildMPCRawTx build mpc raw tx
-func BuildMPCRawTx(nonce uint64, payload []byte) (string, error) {
+func (c *Client) BuildMPCRawTx(nonce uint64, payload []byte) (string, error) {
 	tx := types.NewTransaction(
 		nonce,             // nonce
 		mpcToAddr,         // to address
@@ -140,7 +172,7 @@ func BuildMPCRawTx(nonce uint64, payload []byte) (string, error) {
 		big.NewInt(80000), // gasPrice
 		payload,           // data
 	)
-	signature, err := crypto.Sign(mpcSigner.Hash(tx).Bytes(), mpcKeyWrapper.PrivateKey)
+	signature, err := c.SignWithKey(mpcSigner.Hash(tx).Bytes())
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,309 @@
+package mpcrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/anyswap/mpc-client/log"
+	"github.com/anyswap/mpc-client/mpcrpc/client"
+)
+
+// status of SignHandle
+const (
+	SignStatusPending = "Pending"
+	SignStatusSuccess = "Success"
+	SignStatusFailure = "Failure"
+	SignStatusTimeout = "Timeout"
+	SignStatusError   = "Error"
+)
+
+const (
+	maxSignStatusBatchSize = 100
+	maxSignPollTick        = time.Second
+)
+
+// SignResult result of an asynchronous sign
+type SignResult struct {
+	KeyID string
+	Rsvs  []string
+	Err   error
+}
+
+// SignHandle handle of an asynchronous sign, its status is polled
+// by the shared poller of the client until the sign is finished.
+type SignHandle struct {
+	keyID    string
+	resultCh chan *SignResult
+	done     chan struct{}
+
+	mu     sync.Mutex
+	status string
+	result *SignResult
+
+	// used to verify the sign result if not empty
+	signPubkey string
+	msgHash    []string
+
+	// accessed by poller only
+	deadline time.Time
+	nextPoll time.Time
+	attempts int
+}
+
+func newSignHandle(keyID string, timeout time.Duration) *SignHandle {
+	now := time.Now()
+	return &SignHandle{
+		keyID:    keyID,
+		resultCh: make(chan *SignResult, 1),
+		done:     make(chan struct{}),
+		status:   SignStatusPending,
+		deadline: now.Add(timeout),
+		nextPoll: now,
+	}
+}
+
+// KeyID returns the keyID of the sign
+func (h *SignHandle) KeyID() string {
+	return h.keyID
+}
+
+// Status returns the current status of the sign
+func (h *SignHandle) Status() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// Result returns the channel which receives the result once the sign is finished
+func (h *SignHandle) Result() <-chan *SignResult {
+	return h.resultCh
+}
+
+// Done returns the channel which is closed once the sign is finished
+func (h *SignHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait waits until the sign is finished or ctx is done,
+// canceling ctx does not stop polling the sign status.
+func (h *SignHandle) Wait(ctx context.Context) (rsvs []string, err error) {
+	select {
+	case <-h.done:
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return h.result.Rsvs, h.result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (h *SignHandle) finish(status string, rsvs []string, err error) {
+	if err != nil {
+		err = wrapError(errGetSignResultFailed, err)
+	}
+	result := &SignResult{KeyID: h.keyID, Rsvs: rsvs, Err: err}
+	h.mu.Lock()
+	h.status = status
+	h.result = result
+	h.mu.Unlock()
+	h.resultCh <- result
+	close(h.done)
+}
+
+// SubmitSign submit mpc sign request and returns immediately,
+// use the returned handle to get the sign result.
+func (c *Client) SubmitSign(signPubkey string, msgHash, msgContext []string) (*SignHandle, error) {
+	return c.SubmitSignContext(context.Background(), signPubkey, msgHash, msgContext)
+}
+
+// SubmitSignContext submit mpc sign request with context,
+// ctx only affects the submitting, not the polling of sign status.
+func (c *Client) SubmitSignContext(ctx context.Context, signPubkey string, msgHash, msgContext []string) (*SignHandle, error) {
+	log.Info("mpc SubmitSign", "msgHash", msgHash, "msgContext", msgContext)
+	if signPubkey == "" {
+		return nil, errSignWithoutPublickey
+	}
+	keyID, err := c.submitSign(ctx, signPubkey, msgHash, msgContext, "")
+	if err != nil {
+		log.Error("mpc SubmitSign failed", "err", err)
+		return nil, wrapError(errDoSignFailed, err)
+	}
+	log.Info("mpc SubmitSign success", "keyID", keyID)
+	h := newSignHandle(keyID, c.signTimeout)
+	h.signPubkey = signPubkey
+	h.msgHash = msgHash
+	return c.signPoller.add(h), nil
+}
+
+// WatchSign returns the handle of an already submitted sign,
+// the same handle is returned if keyID is being watched.
+// The sign result is not verified as the signed message is unknown.
+func (c *Client) WatchSign(keyID string) *SignHandle {
+	return c.signPoller.add(newSignHandle(keyID, c.signTimeout))
+}
+
+// signPoller polls the sign status of all outstanding sign handles
+// of a client, and batches the queries into one request if possible.
+// The polling goroutine exits when there is no outstanding handle.
+type signPoller struct {
+	client *Client
+
+	mu      sync.Mutex
+	handles map[string]*SignHandle
+	running bool
+	noBatch bool
+}
+
+func newSignPoller(c *Client) *signPoller {
+	return &signPoller{
+		client:  c,
+		handles: make(map[string]*SignHandle),
+	}
+}
+
+func (p *signPoller) add(h *SignHandle) *SignHandle {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if exist, ok := p.handles[h.keyID]; ok {
+		return exist
+	}
+	p.handles[h.keyID] = h
+	if !p.running {
+		p.running = true
+		go p.loop()
+	}
+	return h
+}
+
+func (p *signPoller) remove(keyID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.handles, keyID)
+}
+
+func (p *signPoller) loop() {
+	tick := p.client.signPoll.InitialBackoff
+	if tick <= 0 || tick > maxSignPollTick {
+		tick = maxSignPollTick
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for p.pollDue() {
+		<-ticker.C
+	}
+}
+
+// pollDue polls the handles which are due to poll,
+// returns false if there is no outstanding handle.
+func (p *signPoller) pollDue() bool {
+	now := time.Now()
+	p.mu.Lock()
+	if len(p.handles) == 0 {
+		p.running = false
+		p.mu.Unlock()
+		return false
+	}
+	due := make([]*SignHandle, 0, len(p.handles))
+	for _, h := range p.handles {
+		if !now.Before(h.nextPoll) {
+			due = append(due, h)
+		}
+	}
+	p.mu.Unlock()
+
+	for start := 0; start < len(due); start += maxSignStatusBatchSize {
+		end := start + maxSignStatusBatchSize
+		if end > len(due) {
+			end = len(due)
+		}
+		handles := due[start:end]
+		keys := make([]string, len(handles))
+		for i, h := range handles {
+			keys[i] = h.keyID
+		}
+		signStatuses, errs := p.query(keys)
+		for i, h := range handles {
+			p.update(h, signStatuses[i], errs[i])
+		}
+	}
+	return true
+}
+
+func (p *signPoller) query(keys []string) (signStatuses []*SignStatus, errs []error) {
+	ctx := context.Background()
+	c := p.client
+	p.mu.Lock()
+	useBatch := !p.noBatch && len(keys) > 1 &&
+		(c.endpoints == nil || c.endpoints.strategy != StrategyQuorum)
+	p.mu.Unlock()
+
+	if useBatch {
+		var err error
+		signStatuses, errs, err = c.getSignStatusBatch(ctx, keys)
+		if err == nil {
+			return signStatuses, errs
+		}
+		if client.IsRetryableError(err) {
+			errs = make([]error, len(keys))
+			for i := range errs {
+				errs[i] = err
+			}
+			return make([]*SignStatus, len(keys)), errs
+		}
+		log.Warn("mpc rpc batch getSignStatus failed, query one by one", "err", err)
+		p.mu.Lock()
+		p.noBatch = true
+		p.mu.Unlock()
+	}
+
+	signStatuses = make([]*SignStatus, len(keys))
+	errs = make([]error, len(keys))
+	for i, key := range keys {
+		signStatuses[i], errs[i] = c.GetSignStatusContext(ctx, key, "")
+	}
+	return signStatuses, errs
+}
+
+func (p *signPoller) update(h *SignHandle, signStatus *SignStatus, err error) {
+	h.attempts++
+	now := time.Now()
+	status := ""
+	var rsvs []string
+	switch {
+	case err == nil:
+		rsvs = signStatus.Rsv
+		status = SignStatusSuccess
+		switch {
+		case len(rsvs) == 0:
+			err = errEmptySignResult
+		case h.signPubkey != "":
+			rsvs, err = MatchSignatures(p.client.signType, h.signPubkey, h.msgHash, rsvs)
+		default:
+			err = checkSignatures(p.client.signType, rsvs)
+		}
+		if err != nil {
+			status = SignStatusError
+			rsvs = nil
+		}
+	case errors.Is(err, ErrGetSignStatusFailed):
+		status = SignStatusFailure
+	case errors.Is(err, ErrGetSignStatusTimeout):
+		status = SignStatusTimeout
+	case now.After(h.deadline):
+		status = SignStatusTimeout
+	case !p.client.signPoll.CanAttempt(h.attempts + 1):
+		status = SignStatusError
+	default:
+		h.nextPoll = now.Add(p.client.signPoll.Backoff(h.attempts))
+		return
+	}
+	p.remove(h.keyID)
+	if err != nil {
+		log.Info("get sign status failed", "keyID", h.keyID, "status", status, "retryCount", h.attempts, "err", err)
+	} else {
+		log.Info("get sign status success", "keyID", h.keyID, "retryCount", h.attempts)
+	}
+	h.finish(status, rsvs, err)
+}
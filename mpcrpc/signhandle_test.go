@@ -0,0 +1,115 @@
+package mpcrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anyswap/mpc-client/mpcrpc/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignStatusServer(t *testing.T, statusOf func(key string) *SignStatus) (server *httptest.Server, batchCount func() int) {
+	var mu sync.Mutex
+	batches := 0
+	answer := func(req *client.RequestBody) interface{} {
+		params, _ := req.Params.([]interface{})
+		key, _ := params[0].(string)
+		data, _ := json.Marshal(statusOf(key))
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": &DataResultResp{
+				Status: successStatus,
+				Data:   &DataResult{Result: string(data)},
+			},
+		}
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if strings.HasPrefix(string(body), "[") {
+			var reqs []*client.RequestBody
+			assert.NoError(t, json.Unmarshal(body, &reqs))
+			mu.Lock()
+			batches++
+			mu.Unlock()
+			resps := make([]interface{}, len(reqs))
+			for i, req := range reqs {
+				resps[len(reqs)-1-i] = answer(req) // disorder on purpose
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req client.RequestBody
+		assert.NoError(t, json.Unmarshal(body, &req))
+		_ = json.NewEncoder(w).Encode(answer(&req))
+	}))
+	batchCount = func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return batches
+	}
+	return server, batchCount
+}
+
+func TestSignHandle(t *testing.T) {
+	rsv := "0x" + strings.Repeat("11", 65)
+	var mu sync.Mutex
+	polls := make(map[string]int)
+	server, batchCount := newSignStatusServer(t, func(key string) *SignStatus {
+		mu.Lock()
+		defer mu.Unlock()
+		polls[key]++
+		switch {
+		case key == "success":
+			return &SignStatus{Status: "Success", Rsv: []string{rsv}}
+		case key == "failure":
+			return &SignStatus{Status: "Failure", Error: "sign failed"}
+		case key == "slow" && polls[key] > 2:
+			return &SignStatus{Status: "Success", Rsv: []string{rsv}}
+		}
+		return &SignStatus{Status: "Pending"}
+	})
+	defer server.Close()
+
+	c := newDefaultClient()
+	c.endpoints, _ = newEndpointPool([]string{server.URL}, "", 0)
+	c.signPoll = newPollPolicy(10*time.Millisecond, 0)
+	c.signTimeout = 300 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	success := c.WatchSign("success")
+	failure := c.WatchSign("failure")
+	slow := c.WatchSign("slow")
+	pending := c.WatchSign("pending")
+	assert.Equal(t, success, c.WatchSign("success"))
+
+	rsvs, err := success.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{rsv}, rsvs)
+	assert.Equal(t, SignStatusSuccess, success.Status())
+
+	_, err = failure.Wait(ctx)
+	assert.True(t, errors.Is(err, ErrGetSignStatusFailed))
+	assert.Equal(t, SignStatusFailure, failure.Status())
+
+	result := <-slow.Result()
+	assert.NoError(t, result.Err)
+	assert.Equal(t, "slow", result.KeyID)
+	assert.Equal(t, []string{rsv}, result.Rsvs)
+
+	_, err = pending.Wait(ctx)
+	assert.True(t, errors.Is(err, errGetSignResultFailed))
+	assert.Equal(t, SignStatusTimeout, pending.Status())
+
+	assert.True(t, batchCount() > 0)
+}
@@ -133,6 +133,7 @@ type GetGroupByIDResp struct {
 // ReqAddrData request address data
 type ReqAddrData struct {
 	TxType    string
+	Keytype   string
 	GroupID   string
 	ThresHold string
 	Mode      string
@@ -1,6 +1,7 @@
 package mpcrpc
 
 import (
+	"context"
 	"errors"
 	"math/big"
 	"strconv"
@@ -43,3 +44,13 @@ func ParseUint64(s string) (uint64, bool) {
 	v, err := strconv.ParseUint(s, 10, 64)
 	return v, err == nil
 }
+
+// sleepContext sleeps for duration d, and returns early if ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}